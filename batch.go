@@ -0,0 +1,155 @@
+package tyrads
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
+)
+
+// BatchResult holds the outcome of one request of a batch.
+type BatchResult struct {
+	Index int
+	Sign  *AuthenticationSign
+	Err   error
+}
+
+// BatchSettings controls how AuthenticateBatch runs.
+type BatchSettings struct {
+	// Concurrency is the maximum number of authentications in flight. Defaults to 4.
+	Concurrency int
+	// RequestsPerSecond paces the batch across all workers. Zero disables pacing, as do rates
+	// too high for a one nanosecond interval. Requests failing validation are not paced.
+	RequestsPerSecond float64
+	// MaxRetries is the number of times an item is retried after a 429 response.
+	MaxRetries int
+	// RetryBackoff is the initial wait before a retry when the server sends no Retry-After.
+	// It doubles on every attempt.
+	RetryBackoff time.Duration
+	// OnProgress is called after every item completes, with the number of completed items.
+	// Calls are serialized.
+	OnProgress func(done, total int, result BatchResult)
+}
+
+type BatchOptions func(*BatchSettings)
+
+// AuthenticateBatch authenticates many users with bounded concurrency.
+// Results are returned in input order, with one entry per request; per-item failures are
+// reported in BatchResult.Err and do not stop the batch.
+//
+// Parameters:
+//   - ctx: Context bounding the whole batch
+//   - requests: The authentication requests to run
+//   - opts: Optional functions adjusting BatchSettings
+//
+// Returns:
+//   - []BatchResult: One result per request, in input order
+//   - error: ctx.Err() if ctx is done when the batch returns, nil otherwise
+func (sdk *TyrAdsSdk) AuthenticateBatch(ctx context.Context, requests []AuthenticationRequest, opts ...BatchOptions) ([]BatchResult, error) {
+	settings := &BatchSettings{
+		Concurrency:  4,
+		MaxRetries:   3,
+		RetryBackoff: time.Second,
+	}
+	for _, opt := range opts {
+		opt(settings)
+	}
+	if settings.Concurrency < 1 {
+		settings.Concurrency = 1
+	}
+
+	results := make([]BatchResult, len(requests))
+	var pace <-chan time.Time
+	if settings.RequestsPerSecond > 0 {
+		if interval := time.Duration(float64(time.Second) / settings.RequestsPerSecond); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			pace = ticker.C
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for w := 0; w < settings.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sign, err := sdk.authenticateWithRetry(ctx, requests[i], settings, pace)
+				results[i] = BatchResult{Index: i, Sign: sign, Err: err}
+
+				mu.Lock()
+				done++
+				if settings.OnProgress != nil {
+					settings.OnProgress(done, len(requests), results[i])
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(requests); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i := next; i < len(requests); i++ {
+			results[i] = BatchResult{Index: i, Err: err}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+func (sdk *TyrAdsSdk) authenticateWithRetry(ctx context.Context, request AuthenticationRequest, settings *BatchSettings, pace <-chan time.Time) (*AuthenticationSign, error) {
+	// Invalid requests fail without waiting for, or consuming, a pacing tick.
+	prepared := request
+	if _, err := sdk.prepareRequest(&prepared); err != nil {
+		return nil, err
+	}
+
+	backoff := settings.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if pace != nil {
+			select {
+			case <-pace:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		sign, err := sdk.AuthenticateWithContext(ctx, request)
+		var httpErr *client.HttpError
+		if err == nil || attempt >= settings.MaxRetries ||
+			!errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+			return sign, err
+		}
+
+		wait := httpErr.RetryAfter
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
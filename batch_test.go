@@ -0,0 +1,211 @@
+package tyrads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+func TestAuthenticateBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprintf(w, `{"data":{"token":"tok-%s"}}`, body["publisherUserId"])
+	}))
+	defer server.Close()
+
+	sdk := newTestSdk(server.URL)
+	requests := []AuthenticationRequest{
+		*contract.NewAuthenticationRequest("u0"),
+		*contract.NewAuthenticationRequest(""),
+		*contract.NewAuthenticationRequest("u2"),
+		*contract.NewAuthenticationRequest("u3"),
+		*contract.NewAuthenticationRequest("u4"),
+	}
+
+	var progress []int
+	results, err := sdk.AuthenticateBatch(context.Background(), requests, func(s *BatchSettings) {
+		s.Concurrency = 2
+		s.OnProgress = func(done, total int, result BatchResult) {
+			if total != len(requests) {
+				t.Errorf("expected total %d, got %d", len(requests), total)
+			}
+			progress = append(progress, done)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != len(requests) {
+		t.Fatalf("expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("expected index %d, got %d", i, result.Index)
+		}
+		if i == 1 {
+			if !errors.Is(result.Err, ErrValidation) {
+				t.Errorf("expected validation error for item 1, got %v", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("unexpected error for item %d: %v", i, result.Err)
+			continue
+		}
+		if expected := fmt.Sprintf("tok-u%d", i); result.Sign.Token != expected {
+			t.Errorf("expected token %s, got %s", expected, result.Sign.Token)
+		}
+	}
+
+	if len(progress) != len(requests) || progress[len(progress)-1] != len(requests) {
+		t.Errorf("unexpected progress calls: %v", progress)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
+
+func TestAuthenticateBatch_RetriesRateLimited(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"token":"tok"}}`))
+	}))
+	defer server.Close()
+
+	sdk := newTestSdk(server.URL)
+	results, err := sdk.AuthenticateBatch(context.Background(),
+		[]AuthenticationRequest{*contract.NewAuthenticationRequest("u0")},
+		func(s *BatchSettings) { s.RetryBackoff = time.Millisecond },
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("expected retry to succeed, got %v", results[0].Err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestAuthenticateBatch_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"token":"tok"}}`))
+	}))
+	defer server.Close()
+
+	sdk := newTestSdk(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	requests := make([]AuthenticationRequest, 10)
+	for i := range requests {
+		requests[i] = *contract.NewAuthenticationRequest(fmt.Sprintf("u%d", i))
+	}
+
+	results, err := sdk.AuthenticateBatch(ctx, requests, func(s *BatchSettings) { s.RequestsPerSecond = 1 })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		if result.Err == nil {
+			t.Errorf("expected error for item %d", i)
+		}
+	}
+}
+
+func TestAuthenticateBatch_CancelledAfterDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.Write([]byte(`{"data":{"token":"tok"}}`))
+	}))
+	defer server.Close()
+
+	sdk := newTestSdk(server.URL)
+	_, err := sdk.AuthenticateBatch(ctx, []AuthenticationRequest{*contract.NewAuthenticationRequest("u0")})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestAuthenticateBatch_Pacing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"token":"tok"}}`))
+	}))
+	defer server.Close()
+
+	sdk := newTestSdk(server.URL)
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		requests          []AuthenticationRequest
+		maxDuration       time.Duration
+	}{
+		{
+			name:              "rate beyond ticker resolution",
+			requestsPerSecond: 2e9,
+			requests:          []AuthenticationRequest{*contract.NewAuthenticationRequest("u0"), *contract.NewAuthenticationRequest("u1")},
+			maxDuration:       time.Second,
+		},
+		{
+			name:              "invalid requests are not paced",
+			requestsPerSecond: 2,
+			requests: []AuthenticationRequest{
+				*contract.NewAuthenticationRequest(""),
+				*contract.NewAuthenticationRequest(""),
+				*contract.NewAuthenticationRequest(""),
+				*contract.NewAuthenticationRequest("u0"),
+			},
+			maxDuration: 900 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			results, err := sdk.AuthenticateBatch(context.Background(), tt.requests, func(s *BatchSettings) {
+				s.Concurrency = 1
+				s.RequestsPerSecond = tt.requestsPerSecond
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("expected batch within %s, took %s", tt.maxDuration, elapsed)
+			}
+			if results[len(results)-1].Err != nil {
+				t.Errorf("expected last item to succeed, got %v", results[len(results)-1].Err)
+			}
+		})
+	}
+}
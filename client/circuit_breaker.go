@@ -0,0 +1,206 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type callOutcome int
+
+const (
+	outcomeSuccess callOutcome = iota
+	outcomeFailure
+	// outcomeIgnored is used for calls that never reached the API or were cancelled by the caller.
+	outcomeIgnored
+)
+
+type stateChange struct {
+	from, to enum.CircuitState
+}
+
+type circuitBreaker struct {
+	mu  sync.Mutex
+	cfg config.CircuitBreakerConfig
+	now func() time.Time
+	// onStateChange, when set, is called after every state transition.
+	onStateChange func(from, to enum.CircuitState)
+	// transitions queues the transitions not yet passed to onStateChange, in order.
+	transitions []stateChange
+	// notifyMu is held by the goroutine delivering transitions, so they are delivered one
+	// at a time and in order.
+	notifyMu sync.Mutex
+	state    enum.CircuitState
+	openedAt time.Time
+	// generation is incremented on every transition, so outcomes of calls allowed in an
+	// earlier state are ignored.
+	generation uint64
+
+	windowStart time.Time
+	successes   int
+	failures    int
+	probes      int
+}
+
+func newCircuitBreaker(cfg *config.CircuitBreakerConfig, onStateChange func(from, to enum.CircuitState)) *circuitBreaker {
+	cb := &circuitBreaker{
+		cfg:           *cfg,
+		now:           time.Now,
+		onStateChange: onStateChange,
+		state:         enum.CircuitClosed,
+	}
+	if cb.cfg.FailureRatio <= 0 {
+		cb.cfg.FailureRatio = 0.5
+	}
+	if cb.cfg.MinRequests <= 0 {
+		cb.cfg.MinRequests = 10
+	}
+	if cb.cfg.Window <= 0 {
+		cb.cfg.Window = time.Minute
+	}
+	if cb.cfg.CoolDown <= 0 {
+		cb.cfg.CoolDown = 30 * time.Second
+	}
+	if cb.cfg.HalfOpenMaxRequests <= 0 {
+		cb.cfg.HalfOpenMaxRequests = 1
+	}
+	return cb
+}
+
+// State returns the current state, moving from open to half-open once the cool-down has elapsed.
+func (cb *circuitBreaker) State() enum.CircuitState {
+	cb.mu.Lock()
+	cb.advance(cb.now())
+	state := cb.state
+	cb.mu.Unlock()
+
+	cb.notify()
+	return state
+}
+
+// allow reports whether a call may proceed and returns the generation it was allowed in.
+// Every allowed call must be followed by record with that generation.
+func (cb *circuitBreaker) allow() (uint64, error) {
+	cb.mu.Lock()
+	cb.advance(cb.now())
+	generation := cb.generation
+	var err error
+	switch cb.state {
+	case enum.CircuitOpen:
+		err = ErrCircuitOpen
+	case enum.CircuitHalfOpen:
+		if cb.probes >= cb.cfg.HalfOpenMaxRequests {
+			err = ErrCircuitOpen
+		} else {
+			cb.probes++
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify()
+	return generation, err
+}
+
+// record reports the outcome of a call allowed by allow in generation. Outcomes of calls
+// allowed before the last transition are ignored.
+func (cb *circuitBreaker) record(generation uint64, outcome callOutcome) {
+	cb.mu.Lock()
+	now := cb.now()
+
+	if generation != cb.generation {
+		cb.mu.Unlock()
+		return
+	}
+	switch cb.state {
+	case enum.CircuitHalfOpen:
+		cb.probes--
+		switch outcome {
+		case outcomeSuccess:
+			cb.transition(enum.CircuitClosed, now)
+		case outcomeFailure:
+			cb.transition(enum.CircuitOpen, now)
+		}
+	case enum.CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.cfg.Window {
+			cb.resetWindow(now)
+		}
+		switch outcome {
+		case outcomeSuccess:
+			cb.successes++
+		case outcomeFailure:
+			cb.failures++
+			total := cb.successes + cb.failures
+			if total >= cb.cfg.MinRequests && float64(cb.failures)/float64(total) >= cb.cfg.FailureRatio {
+				cb.transition(enum.CircuitOpen, now)
+			}
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify()
+}
+
+// advance moves an open circuit to half-open after the cool-down. Must be called with mu held.
+func (cb *circuitBreaker) advance(now time.Time) {
+	if cb.state == enum.CircuitOpen && now.Sub(cb.openedAt) >= cb.cfg.CoolDown {
+		cb.transition(enum.CircuitHalfOpen, now)
+	}
+}
+
+// transition changes the state and queues the change for notify. Must be called with mu held.
+func (cb *circuitBreaker) transition(to enum.CircuitState, now time.Time) {
+	if cb.onStateChange != nil {
+		cb.transitions = append(cb.transitions, stateChange{from: cb.state, to: to})
+	}
+	cb.state = to
+	cb.generation++
+	switch to {
+	case enum.CircuitOpen:
+		cb.openedAt = now
+	case enum.CircuitHalfOpen:
+		cb.probes = 0
+	case enum.CircuitClosed:
+		cb.resetWindow(now)
+	}
+}
+
+func (cb *circuitBreaker) resetWindow(now time.Time) {
+	cb.windowStart = now
+	cb.successes = 0
+	cb.failures = 0
+}
+
+// notify passes the queued transitions to onStateChange in order. Only one goroutine
+// delivers at a time; a call made meanwhile, including from onStateChange itself, leaves its
+// transitions to that goroutine. Must be called without mu held.
+func (cb *circuitBreaker) notify() {
+	for cb.notifyMu.TryLock() {
+		for {
+			cb.mu.Lock()
+			if len(cb.transitions) == 0 {
+				cb.mu.Unlock()
+				break
+			}
+			change := cb.transitions[0]
+			cb.transitions = cb.transitions[1:]
+			cb.mu.Unlock()
+
+			cb.onStateChange(change.from, change.to)
+		}
+		cb.notifyMu.Unlock()
+
+		// A transition queued after the last check but before Unlock would be left behind.
+		cb.mu.Lock()
+		pending := len(cb.transitions) > 0
+		cb.mu.Unlock()
+		if !pending {
+			return
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var transitions []string
+	cb := newCircuitBreaker(&config.CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		CoolDown:     10 * time.Second,
+	}, func(from, to enum.CircuitState) {
+		transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+	})
+	cb.now = func() time.Time { return now }
+
+	outcomes := []callOutcome{outcomeSuccess, outcomeFailure, outcomeSuccess}
+	for _, outcome := range outcomes {
+		generation, err := cb.allow()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cb.record(generation, outcome)
+	}
+	if state := cb.State(); state != enum.CircuitClosed {
+		t.Fatalf("expected closed below MinRequests, got %s", state)
+	}
+
+	generation, _ := cb.allow()
+	cb.record(generation, outcomeFailure)
+	if state := cb.State(); state != enum.CircuitOpen {
+		t.Fatalf("expected open at 50%% failures, got %s", state)
+	}
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	probe, err := cb.allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed after cool-down, got %v", err)
+	}
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second probe to be rejected, got %v", err)
+	}
+	cb.record(probe, outcomeFailure)
+	if state := cb.State(); state != enum.CircuitOpen {
+		t.Fatalf("expected failed probe to reopen, got %s", state)
+	}
+
+	now = now.Add(10 * time.Second)
+	probe, _ = cb.allow()
+	cb.record(probe, outcomeSuccess)
+	if state := cb.State(); state != enum.CircuitClosed {
+		t.Fatalf("expected successful probe to close, got %s", state)
+	}
+
+	expected := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if fmt.Sprint(transitions) != fmt.Sprint(expected) {
+		t.Errorf("expected transitions %v, got %v", expected, transitions)
+	}
+}
+
+func TestCircuitBreaker_WindowReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cb := newCircuitBreaker(&config.CircuitBreakerConfig{MinRequests: 2, Window: time.Minute}, nil)
+	cb.now = func() time.Time { return now }
+
+	generation, _ := cb.allow()
+	cb.record(generation, outcomeFailure)
+	now = now.Add(2 * time.Minute)
+	generation, _ = cb.allow()
+	cb.record(generation, outcomeFailure)
+
+	if state := cb.State(); state != enum.CircuitClosed {
+		t.Errorf("expected failures in different windows not to trip, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_StaleOutcomes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cb := newCircuitBreaker(&config.CircuitBreakerConfig{MinRequests: 1, CoolDown: 10 * time.Second}, nil)
+	cb.now = func() time.Time { return now }
+
+	slow, _ := cb.allow()
+	failing, _ := cb.allow()
+	cb.record(failing, outcomeFailure)
+	if state := cb.State(); state != enum.CircuitOpen {
+		t.Fatalf("expected open after failure, got %s", state)
+	}
+
+	now = now.Add(10 * time.Second)
+	probe, err := cb.allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	cb.record(slow, outcomeSuccess)
+	if state := cb.State(); state != enum.CircuitHalfOpen {
+		t.Fatalf("expected stale success to be ignored while half-open, got %s", state)
+	}
+	if cb.probes != 1 {
+		t.Fatalf("expected 1 probe in flight, got %d", cb.probes)
+	}
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected second probe to be rejected, got %v", err)
+	}
+
+	cb.record(probe, outcomeSuccess)
+	if state := cb.State(); state != enum.CircuitClosed {
+		t.Errorf("expected probe success to close, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_NotifiesInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var clock int64
+	var transitions []enum.CircuitState
+	var cb *circuitBreaker
+	cb = newCircuitBreaker(&config.CircuitBreakerConfig{MinRequests: 1, CoolDown: time.Nanosecond}, func(from, to enum.CircuitState) {
+		cb.State() // calling back into the breaker must not deadlock
+		if len(transitions) > 0 && transitions[len(transitions)-1] != from {
+			t.Errorf("expected transition from %s, got %s->%s", transitions[len(transitions)-1], from, to)
+		}
+		transitions = append(transitions, to)
+	})
+	cb.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		clock++
+		return time.Unix(0, clock)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				generation, err := cb.allow()
+				if err != nil {
+					continue
+				}
+				outcome := outcomeSuccess
+				if (i+j)%2 == 0 {
+					outcome = outcomeFailure
+				}
+				cb.record(generation, outcome)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(transitions) == 0 {
+		t.Fatal("expected transitions")
+	}
+	if last := transitions[len(transitions)-1]; last != cb.state {
+		t.Errorf("expected last notified state %s to be the current state %s", last, cb.state)
+	}
+}
+
+func TestClassifyOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected callOutcome
+	}{
+		{name: "success", err: nil, expected: outcomeSuccess},
+		{name: "cancelled", err: fmt.Errorf("wrapped: %w", context.Canceled), expected: outcomeIgnored},
+		{name: "deadline", err: context.DeadlineExceeded, expected: outcomeFailure},
+		{name: "server error", err: &HttpError{StatusCode: 503}, expected: outcomeFailure},
+		{name: "throttled", err: &HttpError{StatusCode: 429}, expected: outcomeFailure},
+		{name: "client error", err: &HttpError{StatusCode: 401}, expected: outcomeSuccess},
+		{name: "transport error", err: errors.New("connection refused"), expected: outcomeFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyOutcome(tt.err); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDoRequest_CircuitBreakerShortCircuits(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig("test-key", "test-secret", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.CircuitBreaker = &config.CircuitBreakerConfig{MinRequests: 2}
+	})
+	client := NewHttpClient(cfg)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.DoRequest("POST", "/auth", nil); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d unexpectedly short-circuited", i)
+		}
+	}
+	if _, err := client.DoRequest("POST", "/auth", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 server calls, got %d", calls)
+	}
+	if state := client.CircuitState(); state != enum.CircuitOpen {
+		t.Errorf("expected open state, got %s", state)
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+)
+
+// HedgeStats reports how hedging behaved since the client was created.
+type HedgeStats struct {
+	// Requests is the number of calls eligible for hedging.
+	Requests uint64
+	// Hedged is the number of calls for which a hedge was sent.
+	Hedged uint64
+	// HedgeWins is the number of calls answered by the hedge rather than the original attempt.
+	HedgeWins uint64
+}
+
+type hedger struct {
+	delay     time.Duration
+	paths     []string
+	requests  atomic.Uint64
+	hedged    atomic.Uint64
+	hedgeWins atomic.Uint64
+}
+
+// ErrInvalidHedgingDelay is returned by every call of a client configured with a
+// HedgingConfig.Delay that is not positive, which would hedge every request at once.
+var ErrInvalidHedgingDelay = errors.New("hedging delay must be positive")
+
+func newHedger(cfg *config.HedgingConfig) (*hedger, error) {
+	if cfg.Delay <= 0 {
+		return nil, fmt.Errorf("%w, got %s", ErrInvalidHedgingDelay, cfg.Delay)
+	}
+	paths := cfg.Paths
+	if len(paths) == 0 {
+		paths = []string{"/auth"}
+	}
+	return &hedger{
+		delay: cfg.Delay,
+		paths: paths,
+	}, nil
+}
+
+func (h *hedger) applies(path string) bool {
+	return slices.Contains(h.paths, path)
+}
+
+func (h *hedger) stats() HedgeStats {
+	return HedgeStats{
+		Requests:  h.requests.Load(),
+		Hedged:    h.hedged.Load(),
+		HedgeWins: h.hedgeWins.Load(),
+	}
+}
+
+// HedgeStats returns hedging counters. It returns zero values when hedging is not configured.
+func (hc *HttpClient) HedgeStats() HedgeStats {
+	if hc.hedger == nil {
+		return HedgeStats{}
+	}
+	return hc.hedger.stats()
+}
+
+type attemptResult struct {
+	parsed interface{}
+	err    error
+	hedge  bool
+}
+
+// sendHedged sends the call and, if it has not completed after the hedging delay, sends a
+// second attempt. The first usable response is returned and the other attempt is cancelled.
+// A hedge is skipped when the rate limiter has no token immediately available.
+func (hc *HttpClient) sendHedged(ctx context.Context, method, path string, body interface{}) (interface{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attemptResult, 2)
+	attempt := func(hedge bool) {
+		parsed, err := hc.send(ctx, method, path, body)
+		results <- attemptResult{parsed: parsed, err: err, hedge: hedge}
+	}
+
+	hc.hedger.requests.Add(1)
+	go attempt(false)
+	inFlight := 1
+	hedged := false
+
+	timer := time.NewTimer(hc.hedger.delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if hc.limiter == nil || hc.limiter.tryAcquire(path) {
+				hedged = true
+				inFlight++
+				hc.hedger.hedged.Add(1)
+				go attempt(true)
+			}
+		case r := <-results:
+			inFlight--
+			if !hedged || inFlight == 0 || isFinalResult(r.err) {
+				if r.hedge {
+					hc.hedger.hedgeWins.Add(1)
+				}
+				return r.parsed, r.err
+			}
+		}
+	}
+}
+
+// isFinalResult reports whether a result settles the call, so a pending attempt is not worth waiting for.
+func isFinalResult(err error) bool {
+	var httpErr *HttpError
+	if err == nil {
+		return true
+	}
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode < 500 && httpErr.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
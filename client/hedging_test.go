@@ -0,0 +1,169 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+)
+
+func newHedgingClient(t *testing.T, handler http.HandlerFunc, opts ...config.ConfigOptions) *HttpClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]config.ConfigOptions{func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.Hedging = &config.HedgingConfig{Delay: 20 * time.Millisecond}
+	}}, opts...)
+	return NewHttpClient(config.NewConfig("test-key", "test-secret", opts...))
+}
+
+func TestDoRequest_HedgeWinsWhenPrimaryIsSlow(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{}, 1)
+	client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"data":{"token":"hedge"}}`))
+	})
+
+	result, err := client.DoRequest("POST", "/auth", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token := result.(map[string]interface{})["data"].(map[string]interface{})["token"]; token != "hedge" {
+		t.Errorf("expected hedge response, got %v", token)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected slow primary attempt to be cancelled")
+	}
+
+	stats := client.HedgeStats()
+	if stats.Requests != 1 || stats.Hedged != 1 || stats.HedgeWins != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDoRequest_NoHedgeWhenPrimaryIsFast(t *testing.T) {
+	var calls int32
+	client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"data":{"token":"primary"}}`))
+	})
+
+	if _, err := client.DoRequest("POST", "/auth", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if stats := client.HedgeStats(); stats.Requests != 1 || stats.Hedged != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDoRequest_HedgeOnlyForConfiguredPaths(t *testing.T) {
+	var calls int32
+	client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	})
+
+	if _, err := client.DoRequest("GET", "/other", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestDoRequest_HedgeWaitsForOtherAttemptOnServerError(t *testing.T) {
+	var calls int32
+	client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(40 * time.Millisecond)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		time.Sleep(60 * time.Millisecond)
+		w.Write([]byte(`{"data":{"token":"hedge"}}`))
+	})
+
+	if _, err := client.DoRequest("POST", "/auth", nil); err != nil {
+		t.Fatalf("expected hedge to recover from primary failure, got %v", err)
+	}
+}
+
+func TestDoRequest_HedgeSkippedWithoutRateLimitToken(t *testing.T) {
+	var calls int32
+	client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"bad"}`))
+	}, func(c *config.Config) {
+		c.RateLimit = &config.RateLimitConfig{RateLimit: config.RateLimit{RequestsPerSecond: 0.1, Burst: 1}}
+	})
+
+	_, err := client.DoRequest("POST", "/auth", nil)
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected HttpError, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected hedge to be skipped, got %d calls", calls)
+	}
+}
+
+func TestDoRequest_HedgeRequiresPositiveDelay(t *testing.T) {
+	for _, delay := range []time.Duration{0, -time.Millisecond} {
+		var calls int32
+		client := newHedgingClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}, func(c *config.Config) { c.Hedging.Delay = delay })
+
+		if _, err := client.DoRequest("POST", "/auth", nil); !errors.Is(err, ErrInvalidHedgingDelay) {
+			t.Errorf("expected ErrInvalidHedgingDelay for delay %s, got %v", delay, err)
+		}
+		if calls != 0 {
+			t.Errorf("expected no API call for delay %s, got %d", delay, calls)
+		}
+	}
+}
+
+func TestIsFinalResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "success", err: nil, expected: true},
+		{name: "client error", err: &HttpError{StatusCode: 400}, expected: true},
+		{name: "throttled", err: &HttpError{StatusCode: 429}, expected: false},
+		{name: "server error", err: &HttpError{StatusCode: 500}, expected: false},
+		{name: "transport error", err: errors.New("connection reset"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFinalResult(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
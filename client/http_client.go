@@ -2,28 +2,89 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
 )
 
 type HttpClient struct {
-	client *http.Client
-	config *config.Config
+	client  *http.Client
+	config  *config.Config
+	limiter *rateLimiter
+	breaker *circuitBreaker
+	hedger  *hedger
+	// configErr is returned by every call when the configuration is invalid.
+	configErr error
+
+	// OnCircuitStateChange, when set, is called after every transition of the circuit breaker.
+	// Transitions are passed one at a time, in order.
+	OnCircuitStateChange func(from, to enum.CircuitState)
 }
 
+// HttpError is returned by DoRequest when the API responds with a non-2xx status.
 type HttpError struct {
-	Message string `json:"message"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"`
+	// RetryAfter is the delay requested by the server through the Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Error returns the message reported by the API.
+func (e *HttpError) Error() string {
+	return e.Message
+}
+
+// notSentError wraps the failure of a call that was rejected before the request left the process.
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string { return e.err.Error() }
+func (e *notSentError) Unwrap() error { return e.err }
+
+// WasSent reports whether the call that returned err sent its request to the API. It is false
+// for calls rejected by the configuration, the circuit breaker, the rate limiter or while
+// encoding the request, and true for every other outcome, including transport failures.
+func WasSent(err error) bool {
+	var notSent *notSentError
+	return !errors.As(err, &notSent)
 }
 
 func NewHttpClient(cfg *config.Config) *HttpClient {
-	return &HttpClient{
-		client: &http.Client{},
+	hc := &HttpClient{
+		client: &http.Client{Transport: cfg.Transport},
 		config: cfg,
 	}
+	if cfg.RateLimit != nil {
+		hc.limiter = newRateLimiter(cfg.RateLimit)
+	}
+	if cfg.CircuitBreaker != nil {
+		hc.breaker = newCircuitBreaker(cfg.CircuitBreaker, func(from, to enum.CircuitState) {
+			if hc.OnCircuitStateChange != nil {
+				hc.OnCircuitStateChange(from, to)
+			}
+		})
+	}
+	if cfg.Hedging != nil {
+		hc.hedger, hc.configErr = newHedger(cfg.Hedging)
+	}
+	return hc
+}
+
+// CircuitState returns the state of the circuit breaker, or enum.CircuitClosed when none is configured.
+func (hc *HttpClient) CircuitState() enum.CircuitState {
+	if hc.breaker == nil {
+		return enum.CircuitClosed
+	}
+	return hc.breaker.State()
 }
 
 // DoRequest sends an HTTP request and returns the parsed JSON response body and error.
@@ -39,20 +100,86 @@ func NewHttpClient(cfg *config.Config) *HttpClient {
 //   - parsed: The parsed JSON response body (nil if error or result is nil).
 //   - err: Error if the request fails or the response status is not 2xx.
 func (hc *HttpClient) DoRequest(method, path string, body interface{}) (interface{}, error) {
+	return hc.DoRequestWithContext(context.Background(), method, path, body)
+}
+
+// DoRequestWithContext behaves like DoRequest but binds the outgoing request to ctx,
+// so cancellation and deadlines of the caller abort the call.
+// Non-2xx responses are reported as *HttpError. When rate limiting is configured, the call
+// first waits for the limiter and fails with ErrRateLimited if it cannot proceed. When a circuit
+// breaker is configured and open, the call fails immediately with ErrCircuitOpen. When hedging
+// is configured for path, a second attempt may be sent if the first is slow; a hedging delay
+// that is not positive fails every call with ErrInvalidHedgingDelay.
+func (hc *HttpClient) DoRequestWithContext(ctx context.Context, method, path string, body interface{}) (interface{}, error) {
+	if hc.configErr != nil {
+		return nil, &notSentError{hc.configErr}
+	}
+	var generation uint64
+	if hc.breaker != nil {
+		var err error
+		if generation, err = hc.breaker.allow(); err != nil {
+			return nil, &notSentError{err}
+		}
+	}
+
+	if hc.limiter != nil {
+		if err := hc.limiter.wait(ctx, path); err != nil {
+			if hc.breaker != nil {
+				hc.breaker.record(generation, outcomeIgnored)
+			}
+			return nil, &notSentError{err}
+		}
+	}
+
+	var parsed interface{}
+	var err error
+	if hc.hedger != nil && hc.hedger.applies(path) {
+		parsed, err = hc.sendHedged(ctx, method, path, body)
+	} else {
+		parsed, err = hc.send(ctx, method, path, body)
+	}
+	if hc.breaker != nil {
+		hc.breaker.record(generation, classifyOutcome(err))
+	}
+	return parsed, err
+}
+
+// classifyOutcome decides whether a call result counts against the circuit breaker.
+// Server errors, throttling, timeouts and transport failures count as failures;
+// other API errors show the API is responding and count as successes.
+func classifyOutcome(err error) callOutcome {
+	var httpErr *HttpError
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, context.Canceled):
+		return outcomeIgnored
+	case errors.As(err, &httpErr):
+		if httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusTooManyRequests {
+			return outcomeFailure
+		}
+		return outcomeSuccess
+	default:
+		return outcomeFailure
+	}
+}
+
+// send performs a single HTTP call to the API and parses its response.
+func (hc *HttpClient) send(ctx context.Context, method, path string, body interface{}) (interface{}, error) {
 	url := fmt.Sprintf("%s/%s%s", hc.config.SdkApiBaseURL, hc.config.SdkApiVersion, path)
 
 	var reqBody io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
-			return nil, err
+			return nil, &notSentError{err}
 		}
 		reqBody = bytes.NewBuffer(b)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, err
+		return nil, &notSentError{err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -76,12 +203,18 @@ func (hc *HttpClient) DoRequest(method, path string, body interface{}) (interfac
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var errMsg HttpError
-		json.Unmarshal(bodyBytes, &errMsg)
+		errMsg := &HttpError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		json.Unmarshal(bodyBytes, errMsg)
 		if errMsg.Message == "" {
 			errMsg.Message = "Unknown error"
 		}
-		return nil, fmt.Errorf("%s", errMsg.Message)
+		if hc.limiter != nil && resp.StatusCode == http.StatusTooManyRequests {
+			hc.limiter.pause(errMsg.RetryAfter)
+		}
+		return nil, errMsg
 	}
 
 	var parsed any
@@ -90,3 +223,19 @@ func (hc *HttpClient) DoRequest(method, path string, body interface{}) (interfac
 	}
 	return parsed, nil
 }
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -1,10 +1,13 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
 )
@@ -113,6 +116,34 @@ func TestDoRequest_HTTPError(t *testing.T) {
 	if err.Error() != "Invalid API key" {
 		t.Errorf("expected error 'Invalid API key', got '%s'", err.Error())
 	}
+
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected *HttpError, got %T", err)
+	}
+	if httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, httpErr.StatusCode)
+	}
+}
+
+func TestDoRequestWithContext_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig("test-key", "test-secret", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+	})
+	client := NewHttpClient(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.DoRequestWithContext(ctx, "POST", "/auth", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
 }
 
 func TestDoRequest_InvalidJSON(t *testing.T) {
@@ -153,3 +184,81 @@ func TestDoRequest_NetworkError(t *testing.T) {
 		t.Errorf("unexpected error message format: %s", err.Error())
 	}
 }
+
+func TestWasSent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	newClient := func(opts ...config.ConfigOptions) *HttpClient {
+		opts = append([]config.ConfigOptions{func(c *config.Config) { c.SdkApiBaseURL = server.URL }}, opts...)
+		return NewHttpClient(config.NewConfig("test-key", "test-secret", opts...))
+	}
+
+	tests := []struct {
+		name   string
+		client *HttpClient
+		body   interface{}
+		// previousCalls are made before the checked call.
+		previousCalls int
+		expected      bool
+	}{
+		{name: "api error", client: newClient(), expected: true},
+		{name: "unencodable body", client: newClient(), body: make(chan int)},
+		{name: "invalid configuration", client: newClient(func(c *config.Config) { c.Hedging = &config.HedgingConfig{} })},
+		{
+			name: "rate limited",
+			client: newClient(func(c *config.Config) {
+				c.RateLimit = &config.RateLimitConfig{RateLimit: config.RateLimit{RequestsPerSecond: 0.001, Burst: 1}, FailFast: true}
+			}),
+			previousCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.previousCalls; i++ {
+				tt.client.DoRequest("POST", "/auth", nil)
+			}
+			_, err := tt.client.DoRequest("POST", "/auth", tt.body)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if got := WasSent(err); got != tt.expected {
+				t.Errorf("expected WasSent %v, got %v for %v", tt.expected, got, err)
+			}
+		})
+	}
+
+	if !WasSent(nil) {
+		t.Error("expected a successful call to be sent")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "empty", value: "", expected: 0},
+		{name: "seconds", value: "3", expected: 3 * time.Second},
+		{name: "negative", value: "-1", expected: 0},
+		{name: "invalid", value: "soon", expected: 0},
+		{name: "past date", value: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("expected about 1 hour for HTTP date, got %s", got)
+	}
+}
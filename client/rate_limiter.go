@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+)
+
+// ErrRateLimited is returned when the client-side rate limiter rejects a request.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// defaultRateLimitPause is used when the server answers 429 without a Retry-After header.
+const defaultRateLimitPause = time.Second
+
+// tokenBucket is a token bucket whose balance may go negative to queue waiting callers.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit config.RateLimit, now time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   limit.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+// When failFast is set and no token is available, nothing is taken and ok is false.
+func (b *tokenBucket) reserve(now time.Time, failFast bool) (wait time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	if failFast && b.tokens < 1 {
+		return 0, false
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second)), true
+}
+
+// refund returns a token taken by a reservation that was not used.
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// rateLimiter combines a global bucket, per-endpoint buckets and a server-requested pause.
+type rateLimiter struct {
+	global      *tokenBucket
+	endpoints   map[string]*tokenBucket
+	failFast    bool
+	now         func() time.Time
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func newRateLimiter(cfg *config.RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		endpoints: map[string]*tokenBucket{},
+		failFast:  cfg.FailFast,
+		now:       time.Now,
+	}
+	now := rl.now()
+	if cfg.RequestsPerSecond > 0 {
+		rl.global = newTokenBucket(cfg.RateLimit, now)
+	}
+	for path, limit := range cfg.PerEndpoint {
+		if limit.RequestsPerSecond > 0 {
+			rl.endpoints[path] = newTokenBucket(limit, now)
+		}
+	}
+	return rl
+}
+
+// wait blocks until a request to path may be sent, ctx is done, or the limiter fails fast.
+func (rl *rateLimiter) wait(ctx context.Context, path string) error {
+	buckets := rl.bucketsFor(path)
+
+	now := rl.now()
+	rl.mu.Lock()
+	delay := rl.pausedUntil.Sub(now)
+	rl.mu.Unlock()
+	if delay > 0 && rl.failFast {
+		return fmt.Errorf("%w: server requested to retry in %s", ErrRateLimited, delay.Round(time.Millisecond))
+	}
+
+	for i, b := range buckets {
+		wait, ok := b.reserve(now, rl.failFast)
+		if !ok {
+			refundAll(buckets[:i])
+			return ErrRateLimited
+		}
+		delay = max(delay, wait)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		refundAll(buckets)
+		return fmt.Errorf("%w: wait of %s exceeds context deadline", ErrRateLimited, delay.Round(time.Millisecond))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		refundAll(buckets)
+		return ctx.Err()
+	}
+}
+
+// tryAcquire takes a token for path only if one is available immediately.
+func (rl *rateLimiter) tryAcquire(path string) bool {
+	buckets := rl.bucketsFor(path)
+
+	now := rl.now()
+	rl.mu.Lock()
+	paused := now.Before(rl.pausedUntil)
+	rl.mu.Unlock()
+	if paused {
+		return false
+	}
+
+	for i, b := range buckets {
+		if _, ok := b.reserve(now, true); !ok {
+			refundAll(buckets[:i])
+			return false
+		}
+	}
+	return true
+}
+
+// bucketsFor returns the buckets a request to path draws from, most specific first.
+func (rl *rateLimiter) bucketsFor(path string) []*tokenBucket {
+	var buckets []*tokenBucket
+	if b, ok := rl.endpoints[path]; ok {
+		buckets = append(buckets, b)
+	}
+	if rl.global != nil {
+		buckets = append(buckets, rl.global)
+	}
+	return buckets
+}
+
+// pause holds back every request for d, following a 429 response from the server.
+func (rl *rateLimiter) pause(d time.Duration) {
+	if d <= 0 {
+		d = defaultRateLimitPause
+	}
+	until := rl.now().Add(d)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if until.After(rl.pausedUntil) {
+		rl.pausedUntil = until
+	}
+}
+
+func refundAll(buckets []*tokenBucket) {
+	for _, b := range buckets {
+		b.refund()
+	}
+}
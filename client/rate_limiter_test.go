@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := newTokenBucket(config.RateLimit{RequestsPerSecond: 10, Burst: 2}, now)
+
+	for i := 0; i < 2; i++ {
+		if wait, ok := b.reserve(now, false); !ok || wait != 0 {
+			t.Fatalf("expected burst token %d to be free, got wait %s", i, wait)
+		}
+	}
+	if _, ok := b.reserve(now, true); ok {
+		t.Fatal("expected fail fast reservation to be rejected once burst is used")
+	}
+	if wait, ok := b.reserve(now, false); !ok || wait != 100*time.Millisecond {
+		t.Errorf("expected wait of 100ms, got %s", wait)
+	}
+	if wait, _ := b.reserve(now, false); wait != 200*time.Millisecond {
+		t.Errorf("expected queued wait of 200ms, got %s", wait)
+	}
+	if wait, _ := b.reserve(now.Add(time.Second), false); wait != 0 {
+		t.Errorf("expected refilled bucket, got wait %s", wait)
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Run("fail fast", func(t *testing.T) {
+		rl := newRateLimiter(&config.RateLimitConfig{
+			RateLimit: config.RateLimit{RequestsPerSecond: 1, Burst: 1},
+			FailFast:  true,
+		})
+		if err := rl.wait(context.Background(), "/auth"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := rl.wait(context.Background(), "/auth"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("per endpoint", func(t *testing.T) {
+		rl := newRateLimiter(&config.RateLimitConfig{
+			PerEndpoint: map[string]config.RateLimit{"/auth": {RequestsPerSecond: 1, Burst: 1}},
+			FailFast:    true,
+		})
+		if err := rl.wait(context.Background(), "/auth"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := rl.wait(context.Background(), "/auth"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited for /auth, got %v", err)
+		}
+		if err := rl.wait(context.Background(), "/other"); err != nil {
+			t.Errorf("expected other endpoints to be unlimited, got %v", err)
+		}
+	})
+
+	t.Run("blocks until token is available", func(t *testing.T) {
+		rl := newRateLimiter(&config.RateLimitConfig{
+			RateLimit: config.RateLimit{RequestsPerSecond: 50, Burst: 1},
+		})
+		rl.wait(context.Background(), "/auth")
+
+		start := time.Now()
+		if err := rl.wait(context.Background(), "/auth"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+			t.Errorf("expected to wait about 20ms, waited %s", elapsed)
+		}
+	})
+
+	t.Run("deadline shorter than wait", func(t *testing.T) {
+		rl := newRateLimiter(&config.RateLimitConfig{
+			RateLimit: config.RateLimit{RequestsPerSecond: 1, Burst: 1},
+		})
+		rl.wait(context.Background(), "/auth")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := rl.wait(ctx, "/auth"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		rl := newRateLimiter(&config.RateLimitConfig{FailFast: true})
+		rl.pause(time.Minute)
+		if err := rl.wait(context.Background(), "/auth"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited while paused, got %v", err)
+		}
+	})
+}
+
+func TestDoRequest_RateLimitAdaptsToRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"Too many requests"}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig("test-key", "test-secret", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.RateLimit = &config.RateLimitConfig{FailFast: true}
+	})
+	client := NewHttpClient(cfg)
+
+	_, err := client.DoRequest("POST", "/auth", nil)
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) || httpErr.RetryAfter != 30*time.Second {
+		t.Fatalf("expected HttpError with 30s RetryAfter, got %v", err)
+	}
+
+	if _, err := client.DoRequest("POST", "/auth", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited after 429, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 server call, got %d", calls)
+	}
+}
@@ -0,0 +1,179 @@
+// Command tyrads-postback-sim sends signed TyrAds reward callbacks to a local endpoint,
+// so reward crediting can be tested without production traffic.
+//
+// Usage:
+//
+//	TYRADS_API_SECRET=... tyrads-postback-sim -url http://localhost:8080/postback -user 123 -amount 50 -duplicate -tampered
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/postback"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+type variant struct {
+	name         string
+	values       url.Values
+	expectReject bool
+}
+
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	fs := flag.NewFlagSet("tyrads-postback-sim", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("url", "", "callback URL to send postbacks to (required)")
+	user := fs.String("user", "test-user", "publisher user ID to credit")
+	amount := fs.Float64("amount", 100, "reward amount")
+	txn := fs.String("txn", "", "transaction ID (random when empty)")
+	offer := fs.String("offer", "sim-offer", "offer ID")
+	secret := fs.String("secret", "", "API secret used for signing (defaults to $"+string(enum.TYRADS_API_SECRET)+")")
+	duplicate := fs.Bool("duplicate", false, "send the same postback a second time")
+	tampered := fs.Bool("tampered", false, "also send a copy with a modified amount and the original signature")
+	method := fs.String("method", http.MethodGet, "HTTP method: GET sends query parameters, POST sends a form")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout per request")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *target == "" {
+		fmt.Fprintln(stderr, "error: -url is required")
+		fs.Usage()
+		return 2
+	}
+	if *secret == "" {
+		*secret = getenv(string(enum.TYRADS_API_SECRET))
+	}
+	if *secret == "" {
+		fmt.Fprintf(stderr, "error: no API secret, set -secret or %s\n", enum.TYRADS_API_SECRET)
+		return 2
+	}
+	*method = strings.ToUpper(*method)
+	if *method != http.MethodGet && *method != http.MethodPost {
+		fmt.Fprintf(stderr, "error: unsupported method %s\n", *method)
+		return 2
+	}
+	u, err := url.Parse(*target)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: invalid -url: %v\n", err)
+		return 2
+	}
+	if *txn == "" {
+		id, err := randomID()
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		*txn = id
+	}
+
+	event := postback.Event{
+		TransactionID:   *txn,
+		PublisherUserID: *user,
+		Amount:          *amount,
+		OfferID:         *offer,
+		Timestamp:       time.Now(),
+	}
+	// The receiver verifies every parameter it gets, including the ones already in the target
+	// URL, so they are merged into the callback before signing.
+	signed := event.Values()
+	extra := url.Values{}
+	for k, v := range u.Query() {
+		if _, ok := signed[k]; !ok && k != postback.ParamSignature {
+			signed[k] = v
+			extra[k] = v
+		}
+	}
+	signed.Set(postback.ParamSignature, postback.Sign(signed, *secret))
+	u.RawQuery = extra.Encode()
+
+	variants := []variant{{name: "original", values: signed}}
+	if *duplicate {
+		variants = append(variants, variant{name: "duplicate", values: signed})
+	}
+	if *tampered {
+		values := url.Values{}
+		for k, v := range signed {
+			values[k] = v
+		}
+		values.Set(postback.ParamAmount, strconv.FormatFloat(*amount*10, 'f', -1, 64))
+		variants = append(variants, variant{name: "tampered", values: values, expectReject: true})
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	exitCode := 0
+	for _, v := range variants {
+		status, body, err := send(client, *method, u, extra, v.values)
+		if err != nil {
+			fmt.Fprintf(stdout, "%-10s transaction=%s error: %v\n", v.name, *txn, err)
+			exitCode = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%-10s transaction=%s status=%d body=%q\n", v.name, *txn, status, body)
+		if v.expectReject && status >= 200 && status < 300 {
+			fmt.Fprintf(stdout, "warning: endpoint accepted a tampered postback\n")
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// send delivers values to target. extra holds the parameters of the target URL query, which
+// stay in the URL of a POST and are not repeated in its form.
+func send(client *http.Client, method string, target *url.URL, extra, values url.Values) (int, string, error) {
+	u := *target
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		form := url.Values{}
+		for k, v := range values {
+			if _, ok := extra[k]; !ok {
+				form[k] = v
+			}
+		}
+		req, err = http.NewRequest(method, u.String(), strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		u.RawQuery = values.Encode()
+		req, err = http.NewRequest(method, u.String(), nil)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, strings.TrimSpace(string(body)), nil
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate transaction ID: %w", err)
+	}
+	return "sim-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/postback"
+)
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var events []postback.Event
+	server := httptest.NewServer(postback.NewHandler("test-secret", func(ctx context.Context, event postback.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	}))
+	defer server.Close()
+
+	for _, method := range []string{"GET", "POST"} {
+		t.Run(method, func(t *testing.T) {
+			events = nil
+			var stdout, stderr bytes.Buffer
+			getenv := func(key string) string {
+				if key == "TYRADS_API_SECRET" {
+					return "test-secret"
+				}
+				return ""
+			}
+
+			code := run([]string{
+				"-url", server.URL + "/postback?source=sim",
+				"-user", "user123",
+				"-amount", "50",
+				"-txn", "txn-1",
+				"-method", method,
+				"-duplicate",
+				"-tampered",
+			}, &stdout, &stderr, getenv)
+
+			if code != 0 {
+				t.Fatalf("expected exit code 0, got %d: %s %s", code, stdout.String(), stderr.String())
+			}
+			if len(events) != 2 {
+				t.Fatalf("expected original and duplicate to be accepted, got %d events", len(events))
+			}
+			for _, event := range events {
+				if event.TransactionID != "txn-1" || event.PublisherUserID != "user123" || event.Amount != 50 {
+					t.Errorf("unexpected event: %+v", event)
+				}
+			}
+			if !strings.Contains(stdout.String(), "tampered   transaction=txn-1 status=401") {
+				t.Errorf("expected tampered postback to be rejected, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_TamperedAccepted(t *testing.T) {
+	// An endpoint that skips signature verification accepts every variant.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-url", server.URL, "-secret", "test-secret", "-tampered"}, &stdout, &stderr, func(string) string { return "" })
+	if code != 1 {
+		t.Errorf("expected exit code 1 when tampered postback is accepted, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "warning: endpoint accepted a tampered postback") {
+		t.Errorf("expected warning, got:\n%s", stdout.String())
+	}
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing url", args: []string{"-secret", "s"}},
+		{name: "missing secret", args: []string{"-url", "http://localhost"}},
+		{name: "invalid method", args: []string{"-url", "http://localhost", "-secret", "s", "-method", "PUT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr, func(string) string { return "" }); code != 2 {
+				t.Errorf("expected exit code 2, got %d", code)
+			}
+		})
+	}
+}
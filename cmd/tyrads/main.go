@@ -0,0 +1,286 @@
+// Command tyrads authenticates users and prints offerwall URLs, so support engineers
+// can reproduce user issues without writing code.
+//
+// Usage:
+//
+//	tyrads auth   --user 123 [--email alice@example.com] [--json]
+//	tyrads url    --user 123 [--to offers] [--json]
+//	tyrads widget --user 123 [--name premium] [--json]
+//	tyrads check  [--user tyrads-cli-check] [--json]
+//
+// There is no read-only endpoint to validate credentials, so check authenticates a probe
+// user against the live API: the user is created in the publisher account if it does not
+// exist. Use --user to pick a dedicated test user.
+//
+// Credentials are read from TYRADS_API_KEY and TYRADS_API_SECRET unless --key and --secret are given.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// defaultCheckUserID is the probe user authenticated by "tyrads check" unless --user is given.
+const defaultCheckUserID = "tyrads-cli-check"
+
+const usage = `Usage: tyrads <command> [flags]
+
+Commands:
+  auth     authenticate a user and print the token
+  url      authenticate a user and print the offerwall iframe URL
+  widget   authenticate a user and print the premium widget URL
+  check    validate the credentials by authenticating a probe user (creates the user
+           in the publisher account, see --user)
+
+Run "tyrads <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+type globalFlags struct {
+	key       string
+	secret    string
+	lang      string
+	apiURL    string
+	iframeURL string
+	timeout   time.Duration
+	json      bool
+}
+
+type userFlags struct {
+	user      string
+	age       int
+	gender    enum.Gender
+	email     string
+	phone     string
+	userGroup string
+	subs      [5]string
+}
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	global globalFlags
+}
+
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	command := args[0]
+	fs := flag.NewFlagSet("tyrads "+command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	c := &cli{stdout: stdout, stderr: stderr}
+	c.registerGlobalFlags(fs)
+
+	var user userFlags
+	var to, name string
+	switch command {
+	case "auth":
+		registerUserFlags(fs, &user)
+	case "url":
+		registerUserFlags(fs, &user)
+		fs.StringVar(&to, "to", "", "deeplink destination inside the offerwall")
+	case "widget":
+		registerUserFlags(fs, &user)
+		fs.StringVar(&name, "name", "", "premium widget name")
+	case "check":
+		fs.StringVar(&user.user, "user", defaultCheckUserID, "probe publisher user ID; check authenticates it, which creates the user if it does not exist")
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %v\n", fs.Args())
+		return 2
+	}
+	c.applyEnv(getenv)
+
+	if c.global.key == "" || c.global.secret == "" {
+		return c.fail(fmt.Errorf("missing credentials, set --key and --secret or %s and %s", enum.TYRADS_API_KEY, enum.TYRADS_API_SECRET))
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	sdk := c.newSdk()
+	ctx, cancel := context.WithTimeout(context.Background(), c.global.timeout)
+	defer cancel()
+
+	if command == "check" {
+		return c.check(ctx, sdk, user.user)
+	}
+
+	if user.user == "" {
+		fmt.Fprintln(stderr, "error: --user is required")
+		return 2
+	}
+	sign, err := sdk.AuthenticateWithContext(ctx, *user.request(set))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	switch command {
+	case "url":
+		var deeplinkTo *string
+		if set["to"] {
+			deeplinkTo = &to
+		}
+		iframeUrl, err := sdk.IframeUrl(sign, deeplinkTo)
+		if err != nil {
+			return c.fail(err)
+		}
+		return c.print(map[string]string{"url": iframeUrl, "publisherUserId": sign.PublisherUserID}, iframeUrl)
+	case "widget":
+		var widgetName *string
+		if set["name"] {
+			widgetName = &name
+		}
+		widgetUrl, err := sdk.IframePremiumWidget(sign, widgetName)
+		if err != nil {
+			return c.fail(err)
+		}
+		return c.print(map[string]string{"url": widgetUrl, "publisherUserId": sign.PublisherUserID}, widgetUrl)
+	default:
+		return c.print(map[string]string{"token": sign.Token, "publisherUserId": sign.PublisherUserID}, sign.Token)
+	}
+}
+
+// registerGlobalFlags registers the flags shared by every command. Flags backed by an
+// environment variable have no default, so the help output never prints credentials;
+// applyEnv fills them after parsing.
+func (c *cli) registerGlobalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.global.key, "key", "", "API key (defaults to $"+string(enum.TYRADS_API_KEY)+")")
+	fs.StringVar(&c.global.secret, "secret", "", "API secret (defaults to $"+string(enum.TYRADS_API_SECRET)+")")
+	fs.StringVar(&c.global.lang, "lang", "", "language code (defaults to $"+string(enum.TYRADS_LANGUAGE)+")")
+	fs.StringVar(&c.global.apiURL, "api-url", "", "API base URL (defaults to $"+string(enum.TYRADS_API_BASE_URL)+")")
+	fs.StringVar(&c.global.iframeURL, "iframe-url", "", "iframe base URL (defaults to $"+string(enum.TYRADS_IFRAME_BASE_URL)+")")
+	fs.DurationVar(&c.global.timeout, "timeout", 15*time.Second, "timeout for API calls")
+	fs.BoolVar(&c.global.json, "json", false, "print JSON output")
+}
+
+// applyEnv sets the global flags left empty from their environment variables.
+func (c *cli) applyEnv(getenv func(string) string) {
+	for value, name := range map[*string]enum.EnvVar{
+		&c.global.key:       enum.TYRADS_API_KEY,
+		&c.global.secret:    enum.TYRADS_API_SECRET,
+		&c.global.lang:      enum.TYRADS_LANGUAGE,
+		&c.global.apiURL:    enum.TYRADS_API_BASE_URL,
+		&c.global.iframeURL: enum.TYRADS_IFRAME_BASE_URL,
+	} {
+		if *value == "" {
+			*value = getenv(string(name))
+		}
+	}
+}
+
+func registerUserFlags(fs *flag.FlagSet, u *userFlags) {
+	fs.StringVar(&u.user, "user", "", "publisher user ID (required)")
+	fs.IntVar(&u.age, "age", 0, "user age")
+	fs.TextVar(&u.gender, "gender", enum.GenderUnspecified, "user gender: male, female, m, f, 1 or 2")
+	fs.StringVar(&u.email, "email", "", "user email")
+	fs.StringVar(&u.phone, "phone", "", "user phone number")
+	fs.StringVar(&u.userGroup, "user-group", "", "user group")
+	for i := range u.subs {
+		fs.StringVar(&u.subs[i], fmt.Sprintf("sub%d", i+1), "", fmt.Sprintf("sub%d tracking value", i+1))
+	}
+}
+
+// request builds the AuthenticationRequest from the flags that were explicitly set.
+func (u *userFlags) request(set map[string]bool) *contract.AuthenticationRequest {
+	var opts []contract.AuthenticationRequestOptions
+	if set["age"] {
+		opts = append(opts, contract.WithAge(u.age))
+	}
+	if set["gender"] {
+		opts = append(opts, contract.WithGender(u.gender))
+	}
+	if set["email"] {
+		opts = append(opts, contract.WithEmail(u.email))
+	}
+	if set["phone"] {
+		opts = append(opts, contract.WithPhoneNumber(u.phone))
+	}
+	if set["user-group"] {
+		opts = append(opts, contract.WithUserGroup(u.userGroup))
+	}
+	for i, sub := range u.subs {
+		if set[fmt.Sprintf("sub%d", i+1)] {
+			opts = append(opts, contract.WithSub(i+1, sub))
+		}
+	}
+	return contract.NewAuthenticationRequest(u.user, opts...)
+}
+
+func (c *cli) newSdk() *tyrads.TyrAdsSdk {
+	return tyrads.NewTyrAdsSdk(c.global.key, c.global.secret, c.global.lang, func(cfg *config.Config) {
+		if c.global.apiURL != "" {
+			cfg.SdkApiBaseURL = c.global.apiURL
+		}
+		if c.global.iframeURL != "" {
+			cfg.IFrameBaseURL = c.global.iframeURL
+		}
+	})
+}
+
+// check authenticates the probe user to confirm the credentials are accepted. This is not
+// free of side effects: the API registers the probe user like any other user.
+func (c *cli) check(ctx context.Context, sdk *tyrads.TyrAdsSdk, probeUserID string) int {
+	if probeUserID == "" {
+		fmt.Fprintln(c.stderr, "error: --user must not be empty")
+		return 2
+	}
+	if _, err := sdk.AuthenticateWithContext(ctx, *contract.NewAuthenticationRequest(probeUserID)); err != nil {
+		return c.fail(err)
+	}
+	return c.print(map[string]string{"status": "ok"}, "credentials OK")
+}
+
+func (c *cli) print(jsonValue interface{}, text string) int {
+	if c.global.json {
+		json.NewEncoder(c.stdout).Encode(jsonValue)
+	} else {
+		fmt.Fprintln(c.stdout, text)
+	}
+	return 0
+}
+
+func (c *cli) fail(err error) int {
+	if c.global.json {
+		out := map[string]interface{}{"error": err.Error()}
+		var httpErr *client.HttpError
+		if errors.As(err, &httpErr) {
+			out["status"] = httpErr.StatusCode
+		}
+		json.NewEncoder(c.stdout).Encode(out)
+	} else {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+	}
+	return 1
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/tyradstest"
+)
+
+func testEnv(server *tyradstest.Server) func(string) string {
+	env := map[string]string{
+		"TYRADS_API_KEY":      tyradstest.DefaultApiKey,
+		"TYRADS_API_SECRET":   tyradstest.DefaultApiSecret,
+		"TYRADS_API_BASE_URL": server.URL,
+	}
+	return func(key string) string { return env[key] }
+}
+
+func TestRun_Commands(t *testing.T) {
+	server := tyradstest.NewServer(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "auth", args: []string{"auth", "--user", "123"}, expected: "token-123\n"},
+		{name: "url", args: []string{"url", "--user", "123", "--to", "offers"}, expected: "https://sdk.tyrads.com?token=token-123&to=offers\n"},
+		{name: "widget", args: []string{"widget", "--user", "123", "--name", "premium"}, expected: "https://sdk.tyrads.com/widget?token=token-123&name=premium\n"},
+		{name: "check", args: []string{"check"}, expected: "credentials OK\n"},
+		{name: "auth json", args: []string{"auth", "--user", "123", "--json"}, expected: `{"publisherUserId":"123","token":"token-123"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr, testEnv(server)); code != 0 {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			if stdout.String() != tt.expected {
+				t.Errorf("expected output %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_SendsProfileFlags(t *testing.T) {
+	server := tyradstest.NewServer(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"auth", "--user", "123", "--email", "alice@example.com", "--age", "30", "--sub2", "campaign"}, &stdout, &stderr, testEnv(server))
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	server.AssertLastAuthField(t, "email", "alice@example.com")
+	server.AssertLastAuthField(t, "age", float64(30))
+	server.AssertLastAuthField(t, "sub2", "campaign")
+	if _, ok := server.AuthRequests()[0].Body["gender"]; ok {
+		t.Error("expected unset gender flag to be omitted")
+	}
+
+	code = run([]string{"auth", "--user", "123", "--gender", "F"}, &stdout, &stderr, testEnv(server))
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	server.AssertLastAuthField(t, "gender", float64(2))
+}
+
+func TestRun_CheckProbeUser(t *testing.T) {
+	server := tyradstest.NewServer(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "default", args: []string{"check"}, expected: "tyrads-cli-check"},
+		{name: "custom", args: []string{"check", "--user", "probe-1"}, expected: "probe-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr, testEnv(server)); code != 0 {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			server.AssertLastAuthField(t, "publisherUserId", tt.expected)
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"check", "--user", ""}, &stdout, &stderr, testEnv(server)); code != 2 {
+		t.Errorf("expected exit code 2 for an empty probe user, got %d", code)
+	}
+}
+
+func TestRun_HelpHidesCredentials(t *testing.T) {
+	server := tyradstest.NewServer(t)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"auth", "-h"}, &stdout, &stderr, testEnv(server)); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if strings.Contains(stderr.String(), tyradstest.DefaultApiSecret) || strings.Contains(stderr.String(), tyradstest.DefaultApiKey) {
+		t.Errorf("expected help not to print credentials, got %s", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "$TYRADS_API_SECRET") {
+		t.Errorf("expected help to name the environment variable, got %s", stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"auth", "--user", "123", "--secret", "wrong"}, &stdout, &stderr, testEnv(server)); code != 1 {
+		t.Errorf("expected the --secret flag to override the environment, got exit code %d", code)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	server := tyradstest.NewServer(t)
+
+	t.Run("api error in json mode", func(t *testing.T) {
+		server.FailNext(1, http.StatusUnauthorized, "Invalid API key")
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"check", "--json"}, &stdout, &stderr, testEnv(server)); code != 1 {
+			t.Fatalf("expected exit code 1, got %d", code)
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("expected JSON error output, got %q", stdout.String())
+		}
+		if out["status"] != float64(http.StatusUnauthorized) || !strings.Contains(out["error"].(string), "Invalid API key") {
+			t.Errorf("unexpected error output: %v", out)
+		}
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"check"}, &stdout, &stderr, func(string) string { return "" }); code != 1 {
+			t.Errorf("expected exit code 1, got %d", code)
+		}
+	})
+
+	usageErrors := [][]string{
+		{},
+		{"unknown"},
+		{"auth"},
+		{"url", "--user", "1", "extra"},
+		{"auth", "--bogus"},
+	}
+	for _, args := range usageErrors {
+		t.Run("usage "+strings.Join(args, " "), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(args, &stdout, &stderr, testEnv(server)); code != 2 {
+				t.Errorf("expected exit code 2, got %d", code)
+			}
+		})
+	}
+}
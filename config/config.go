@@ -1,5 +1,10 @@
 package config
 
+import (
+	"net/http"
+	"time"
+)
+
 type Config struct {
 	IFrameBaseURL string
 	SdkApiBaseURL string
@@ -8,6 +13,41 @@ type Config struct {
 	ApiKey        string
 	ApiSecret     string
 	Language      string
+	// Transport sends the API requests. Defaults to http.DefaultTransport when nil.
+	Transport http.RoundTripper
+	// LaunchUrlTTL is the validity window of signed launch URLs. It must be positive.
+	LaunchUrlTTL time.Duration
+	// RateLimit enables client-side rate limiting of API calls when set.
+	RateLimit *RateLimitConfig
+	// CircuitBreaker enables the client-side circuit breaker when set.
+	CircuitBreaker *CircuitBreakerConfig
+	// Hedging enables hedged requests for latency-sensitive endpoints when set.
+	Hedging *HedgingConfig
+	// DefaultPhoneCountryCode is the calling code, such as "1" or "44", applied to phone
+	// numbers given without one before they are normalized to E.164. When empty, such
+	// numbers are sent as national digits.
+	DefaultPhoneCountryCode string
+	// AgePolicy enforces a minimum user age when set.
+	AgePolicy *AgePolicyConfig
+	// Now returns the current time, used to derive ages from birth dates and to stamp
+	// signed launch URLs. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// RateLimit describes a token bucket refilled at RequestsPerSecond and holding up to Burst tokens.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimitConfig configures the client-side rate limiter.
+type RateLimitConfig struct {
+	// RateLimit applies to every API call. A zero RequestsPerSecond disables the global limit.
+	RateLimit
+	// PerEndpoint adds limits for specific API paths, such as "/auth".
+	PerEndpoint map[string]RateLimit
+	// FailFast returns client.ErrRateLimited immediately instead of waiting for a token.
+	FailFast bool
 }
 
 type ConfigOptions func(*Config)
@@ -21,6 +61,8 @@ func NewConfig(apiKey, apiSecret string, opts ...ConfigOptions) *Config {
 	c.ApiKey = apiKey
 	c.ApiSecret = apiSecret
 	c.Language = "en"
+	c.LaunchUrlTTL = 5 * time.Minute
+	c.Now = time.Now
 
 	for _, opt := range opts {
 		opt(c)
@@ -28,3 +70,35 @@ func NewConfig(apiKey, apiSecret string, opts ...ConfigOptions) *Config {
 
 	return c
 }
+
+// CircuitBreakerConfig configures the client-side circuit breaker.
+// Zero values are replaced by the defaults noted on each field.
+type CircuitBreakerConfig struct {
+	// FailureRatio opens the circuit once this share of calls in the window failed. Defaults to 0.5.
+	FailureRatio float64
+	// MinRequests is the number of calls needed in the window before the ratio is evaluated. Defaults to 10.
+	MinRequests int
+	// Window is the period over which calls are counted. Defaults to 1 minute.
+	Window time.Duration
+	// CoolDown is how long the circuit stays open before probing the API again. Defaults to 30 seconds.
+	CoolDown time.Duration
+	// HalfOpenMaxRequests is the number of concurrent probe calls allowed while half-open. Defaults to 1.
+	HalfOpenMaxRequests int
+}
+
+// HedgingConfig configures hedged requests: when a call has not completed after Delay,
+// a second identical call is sent and the first usable response wins.
+type HedgingConfig struct {
+	// Delay before the hedge is sent, typically the endpoint's p95 latency. It must be positive.
+	Delay time.Duration
+	// Paths lists the API paths eligible for hedging. Defaults to "/auth".
+	Paths []string
+}
+
+// AgePolicyConfig configures how requests for users below a minimum age are handled.
+type AgePolicyConfig struct {
+	// MinimumAge is the youngest accepted age, such as 13.
+	MinimumAge int
+	// DropMinorAge sends requests for younger users without their age instead of rejecting them.
+	DropMinorAge bool
+}
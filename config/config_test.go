@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNewConfig(t *testing.T) {
 	tests := []struct {
@@ -22,6 +25,7 @@ func TestNewConfig(t *testing.T) {
 				ApiKey:        "test-key",
 				ApiSecret:     "test-secret",
 				Language:      "en",
+				LaunchUrlTTL:  5 * time.Minute,
 			},
 		},
 		{
@@ -39,6 +43,7 @@ func TestNewConfig(t *testing.T) {
 				ApiKey:        "test-key",
 				ApiSecret:     "test-secret",
 				Language:      "es",
+				LaunchUrlTTL:  5 * time.Minute,
 			},
 		},
 		{
@@ -56,6 +61,7 @@ func TestNewConfig(t *testing.T) {
 				ApiKey:        "test-key",
 				ApiSecret:     "test-secret",
 				Language:      "en",
+				LaunchUrlTTL:  5 * time.Minute,
 			},
 		},
 	}
@@ -85,6 +91,12 @@ func TestNewConfig(t *testing.T) {
 			if config.Language != tt.expected.Language {
 				t.Errorf("expected Language %s, got %s", tt.expected.Language, config.Language)
 			}
+			if config.LaunchUrlTTL != tt.expected.LaunchUrlTTL {
+				t.Errorf("expected LaunchUrlTTL %s, got %s", tt.expected.LaunchUrlTTL, config.LaunchUrlTTL)
+			}
+			if config.Now == nil {
+				t.Error("expected Now to default to time.Now")
+			}
 		})
 	}
 }
@@ -0,0 +1,126 @@
+package contract
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// AttributionSource lists where the value of one field is read from. The first non-empty
+// query parameter wins, then the first non-empty cookie.
+type AttributionSource struct {
+	Params  []string
+	Cookies []string
+}
+
+// AttributionMapping maps the payload key of a tracking field, such as "sub1" or
+// "mediaCampaignName", to its source. Keys that are not tracking fields are ignored.
+type AttributionMapping map[string]AttributionSource
+
+// DefaultAttributionMapping reads these query parameters, in order:
+//
+//	mediaSourceName    media_source, utm_source
+//	mediaSourceId      media_source_id
+//	mediaSubSourceId   media_sub_source_id
+//	mediaCampaignName  campaign, utm_campaign
+//	mediaAdsetName     adset
+//	mediaAdsetId       adset_id
+//	mediaCreativeName  creative, utm_content
+//	mediaCreativeId    creative_id
+//	sub1               sub1, utm_term
+//	sub2               sub2, utm_medium
+//	sub3 to sub5       sub3 to sub5
+func DefaultAttributionMapping() AttributionMapping {
+	return AttributionMapping{
+		"mediaSourceName":   {Params: []string{"media_source", "utm_source"}},
+		"mediaSourceId":     {Params: []string{"media_source_id"}},
+		"mediaSubSourceId":  {Params: []string{"media_sub_source_id"}},
+		"mediaCampaignName": {Params: []string{"campaign", "utm_campaign"}},
+		"mediaAdsetName":    {Params: []string{"adset"}},
+		"mediaAdsetId":      {Params: []string{"adset_id"}},
+		"mediaCreativeName": {Params: []string{"creative", "utm_content"}},
+		"mediaCreativeId":   {Params: []string{"creative_id"}},
+		"sub1":              {Params: []string{"sub1", "utm_term"}},
+		"sub2":              {Params: []string{"sub2", "utm_medium"}},
+		"sub3":              {Params: []string{"sub3"}},
+		"sub4":              {Params: []string{"sub4"}},
+		"sub5":              {Params: []string{"sub5"}},
+	}
+}
+
+// AttributionMapper extracts attribution values from an *http.Request into the sub and
+// media fields of an AuthenticationRequest.
+type AttributionMapper struct {
+	// Mapping selects the sources of each field. Defaults to DefaultAttributionMapping.
+	Mapping AttributionMapping
+	// MaxLength truncates values to this many characters. Defaults to MaxFieldLength.
+	MaxLength int
+}
+
+type AttributionMapperOptions func(*AttributionMapper)
+
+// NewAttributionMapper creates an AttributionMapper with the default mapping.
+func NewAttributionMapper(opts ...AttributionMapperOptions) *AttributionMapper {
+	m := &AttributionMapper{
+		Mapping:   DefaultAttributionMapping(),
+		MaxLength: MaxFieldLength,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Options returns the options setting every mapped field found in r. Values are trimmed,
+// stripped of control characters and truncated to MaxLength, so they pass validation.
+//
+//	req := contract.NewAuthenticationRequest(userID, mapper.Options(r)...)
+func (m *AttributionMapper) Options(r *http.Request) []AuthenticationRequestOptions {
+	query := r.URL.Query()
+	var opts []AuthenticationRequestOptions
+	for key, source := range m.Mapping {
+		if (&AuthenticationRequest{}).textFieldByKey(key) == nil {
+			continue
+		}
+		value := m.clean(lookupAttribution(r, query, source))
+		if value == "" {
+			continue
+		}
+		opts = append(opts, func(ar *AuthenticationRequest) {
+			*ar.textFieldByKey(key) = &value
+		})
+	}
+	return opts
+}
+
+func lookupAttribution(r *http.Request, query map[string][]string, source AttributionSource) string {
+	for _, param := range source.Params {
+		if values := query[param]; len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+			return values[0]
+		}
+	}
+	for _, name := range source.Cookies {
+		if cookie, err := r.Cookie(name); err == nil && strings.TrimSpace(cookie.Value) != "" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func (m *AttributionMapper) clean(value string) string {
+	value = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, value))
+
+	maxLength := m.MaxLength
+	if maxLength <= 0 || maxLength > MaxFieldLength {
+		maxLength = MaxFieldLength
+	}
+	if runes := []rune(value); len(runes) > maxLength {
+		value = string(runes[:maxLength])
+	}
+	return value
+}
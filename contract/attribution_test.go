@@ -0,0 +1,93 @@
+package contract
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAttributionMapper_DefaultMapping(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/offers?utm_source=google&media_source=facebook&utm_campaign=spring&utm_content=banner&creative_id=c-1&utm_term=shoes&sub3=x&unknown=y", nil)
+
+	req := NewAuthenticationRequest("user123", NewAttributionMapper().Options(r)...)
+
+	expected := map[string]interface{}{
+		"publisherUserId":   "user123",
+		"mediaSourceName":   "facebook",
+		"mediaCampaignName": "spring",
+		"mediaCreativeName": "banner",
+		"mediaCreativeId":   "c-1",
+		"sub1":              "shoes",
+		"sub3":              "x",
+	}
+	result := req.GetParsedAuthenticationRequestData()
+	if len(result) != len(expected) {
+		t.Fatalf("expected %+v, got %+v", expected, result)
+	}
+	for key, value := range expected {
+		if result[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, result[key])
+		}
+	}
+}
+
+func TestAttributionMapper_CustomMapping(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/offers?src=&clickid=abc", nil)
+	r.AddCookie(&http.Cookie{Name: "attr_src", Value: "newsletter"})
+
+	mapper := NewAttributionMapper(func(m *AttributionMapper) {
+		m.Mapping = AttributionMapping{
+			"mediaSourceName": {Params: []string{"src"}, Cookies: []string{"attr_src"}},
+			"sub5":            {Params: []string{"clickid"}},
+			"email":           {Params: []string{"clickid"}},
+		}
+	})
+	req := NewAuthenticationRequest("user123", mapper.Options(r)...)
+
+	if req.MediaSourceName == nil || *req.MediaSourceName != "newsletter" {
+		t.Errorf("expected cookie fallback for empty parameter, got %v", req.MediaSourceName)
+	}
+	if req.Sub5 == nil || *req.Sub5 != "abc" {
+		t.Errorf("expected sub5 'abc', got %v", req.Sub5)
+	}
+	if req.Email != nil {
+		t.Errorf("expected non-tracking key to be ignored, got %s", *req.Email)
+	}
+}
+
+func TestAttributionMapper_Clean(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		value     string
+		expected  string
+	}{
+		{name: "trimmed", value: "  spring  ", expected: "spring"},
+		{name: "control characters", value: "spring\r\nsale", expected: "springsale"},
+		{name: "truncated to max length", maxLength: 3, value: "ééééé", expected: "ééé"},
+		{name: "default max length", value: strings.Repeat("a", MaxFieldLength+10), expected: strings.Repeat("a", MaxFieldLength)},
+		{name: "max length capped", maxLength: MaxFieldLength * 2, value: strings.Repeat("a", MaxFieldLength+10), expected: strings.Repeat("a", MaxFieldLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAttributionMapper()
+			if tt.maxLength > 0 {
+				m.MaxLength = tt.maxLength
+			}
+			if got := m.clean(tt.value); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAttributionMapper_ProducesValidRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/offers?utm_campaign="+strings.Repeat("x", 1000)+"%00&sub2=%09tab", nil)
+
+	req := NewAuthenticationRequest("user123", NewAttributionMapper().Options(r)...)
+	if err := req.ValidateAuthenticationRequest(); err != nil {
+		t.Errorf("expected mapped request to be valid, got %v", err)
+	}
+}
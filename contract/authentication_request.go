@@ -1,33 +1,63 @@
 package contract
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
 )
 
 // AuthenticationRequest represents a request for user authentication.
 type AuthenticationRequest struct {
-	PublisherUserID   string  `json:"publisherUserId"`
-	Age               *int    `json:"age,omitempty"`
-	Gender            *int    `json:"gender,omitempty"`
-	Email             *string `json:"email,omitempty"`
-	PhoneNumber       *string `json:"phoneNumber,omitempty"`
-	Sub1              *string `json:"sub1,omitempty"`
-	Sub2              *string `json:"sub2,omitempty"`
-	Sub3              *string `json:"sub3,omitempty"`
-	Sub4              *string `json:"sub4,omitempty"`
-	Sub5              *string `json:"sub5,omitempty"`
-	UserGroup         *string `json:"userGroup,omitempty"`
-	MediaSourceName   *string `json:"mediaSourceName,omitempty"`
-	MediaSourceID     *string `json:"mediaSourceId,omitempty"`
-	MediaSubSourceID  *string `json:"mediaSubSourceId,omitempty"`
-	Incentivized      *bool   `json:"incentivized,omitempty"`
-	MediaAdsetName    *string `json:"mediaAdsetName,omitempty"`
-	MediaAdsetID      *string `json:"mediaAdsetId,omitempty"`
-	MediaCreativeName *string `json:"mediaCreativeName,omitempty"`
-	MediaCreativeID   *string `json:"mediaCreativeId,omitempty"`
-	MediaCampaignName *string `json:"mediaCampaignName,omitempty"`
+	PublisherUserID   string       `json:"publisherUserId"`
+	Age               *int         `json:"age,omitempty"`
+	Gender            *enum.Gender `json:"gender,omitempty"`
+	Email             *string      `json:"email,omitempty"`
+	PhoneNumber       *string      `json:"phoneNumber,omitempty"`
+	Sub1              *string      `json:"sub1,omitempty"`
+	Sub2              *string      `json:"sub2,omitempty"`
+	Sub3              *string      `json:"sub3,omitempty"`
+	Sub4              *string      `json:"sub4,omitempty"`
+	Sub5              *string      `json:"sub5,omitempty"`
+	UserGroup         *string      `json:"userGroup,omitempty"`
+	MediaSourceName   *string      `json:"mediaSourceName,omitempty"`
+	MediaSourceID     *string      `json:"mediaSourceId,omitempty"`
+	MediaSubSourceID  *string      `json:"mediaSubSourceId,omitempty"`
+	Incentivized      *bool        `json:"incentivized,omitempty"`
+	MediaAdsetName    *string      `json:"mediaAdsetName,omitempty"`
+	MediaAdsetID      *string      `json:"mediaAdsetId,omitempty"`
+	MediaCreativeName *string      `json:"mediaCreativeName,omitempty"`
+	MediaCreativeID   *string      `json:"mediaCreativeId,omitempty"`
+	MediaCampaignName *string      `json:"mediaCampaignName,omitempty"`
+	// GdprApplies reports whether the user is subject to GDPR.
+	GdprApplies *bool `json:"gdprApplies,omitempty"`
+	// TcfConsent is the IAB TCF v2 consent string.
+	TcfConsent *string `json:"tcfConsent,omitempty"`
+	// UsPrivacy is the IAB US privacy (CCPA) string, such as "1YNN".
+	UsPrivacy *string `json:"usPrivacy,omitempty"`
+	// LimitAdTracking reports whether the user opted out of ad tracking on their device.
+	LimitAdTracking *bool `json:"limitAdTracking,omitempty"`
+	// ChildDirected marks the user as subject to COPPA.
+	ChildDirected *bool `json:"childDirected,omitempty"`
+	// Region is the ISO 3166 code of the user's region, such as "DE" or "US-CA".
+	Region *string `json:"region,omitempty"`
+	// BirthDate is not sent; the SDK derives Age from it when Age is not set.
+	BirthDate *time.Time `json:"-"`
+	// Extra holds additional payload parameters, for API fields the SDK does not model yet.
+	// Keys must not collide with the keys of the fields above.
+	Extra map[string]any `json:"-"`
+	// PrivacyMode overrides TyrAdsSdk.PrivacyMode for this request when set.
+	PrivacyMode enum.PrivacyMode `json:"-"`
+
+	// optionErrs holds the errors recorded by options as they were applied, such as an
+	// out-of-range WithSub index, which leave no trace in the fields themselves.
+	optionErrs ValidationErrors
 }
 
 type AuthenticationRequestOptions func(*AuthenticationRequest)
@@ -45,64 +75,248 @@ func NewAuthenticationRequest(publisherUserID string, opts ...AuthenticationRequ
 	return req
 }
 
-// ValidateAuthenticationRequest validates an AuthenticationRequest.
-// Returns error if validation fails.
+var emailRegex = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+
+// MaxAge is the oldest accepted user age.
+const MaxAge = 120
+
+// MaxFieldLength is the maximum length, in characters, of the sub, user group and media fields.
+const MaxFieldLength = 255
+
+// ValidateAuthenticationRequest validates an AuthenticationRequest as of the current time.
+// Returns a ValidationErrors listing every violation, or nil if the request is valid.
 func (ar *AuthenticationRequest) ValidateAuthenticationRequest() error {
+	return ar.ValidateAuthenticationRequestAt(time.Now())
+}
+
+// ValidateAuthenticationRequestAt behaves like ValidateAuthenticationRequest, checking the
+// birth date against now instead of the current time.
+func (ar *AuthenticationRequest) ValidateAuthenticationRequestAt(now time.Time) error {
+	return ar.validationErrors(now).err()
+}
+
+// validationErrors returns every violation found in the request as of now, in field order.
+func (ar *AuthenticationRequest) validationErrors(now time.Time) ValidationErrors {
+	errs := append(ValidationErrors(nil), ar.optionErrs...)
 	if ar.PublisherUserID == "" {
-		return errors.New("publisher user ID cannot be empty and must be a string")
+		errs = append(errs, &FieldError{Field: "PublisherUserID", JSONKey: "publisherUserId", Rule: RuleRequired, Message: "publisher user ID cannot be empty and must be a string"})
+	} else if hasControlChars(ar.PublisherUserID) {
+		errs = append(errs, controlCharsError("PublisherUserID", "publisherUserId"))
 	}
-	if ar.Age != nil && *ar.Age < 0 {
-		return errors.New("age must be a non-negative integer")
+	if ar.Age != nil {
+		if err := validateAge(*ar.Age); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	if ar.Gender != nil && (*ar.Gender != 1 && *ar.Gender != 2) {
-		return errors.New("gender must be either 1 (male) or 2 (female)")
+	if ar.BirthDate != nil && ar.BirthDate.After(now) {
+		errs = append(errs, &FieldError{Field: "BirthDate", JSONKey: "birthDate", Rule: RuleRange, Message: "birth date must not be in the future"})
+	}
+	if ar.hasGender() {
+		if err := validateGender(*ar.Gender); err != nil {
+			errs = append(errs, err)
+		}
 	}
 	if ar.Email != nil {
-		emailRegex := regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
-		if !emailRegex.MatchString(*ar.Email) {
-			return errors.New("invalid email format")
+		if err := validateEmail(*ar.Email); err != nil {
+			errs = append(errs, err)
 		}
 	}
 	if ar.PhoneNumber != nil {
-		phoneRegex := regexp.MustCompile(`^\+?[0-9\- ]{7,20}$`)
-		if !phoneRegex.MatchString(*ar.PhoneNumber) {
-			return errors.New("invalid phone number format")
+		if err := validatePhoneNumber(*ar.PhoneNumber); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	stringFields := map[string]*string{
-		"sub1":              ar.Sub1,
-		"sub2":              ar.Sub2,
-		"sub3":              ar.Sub3,
-		"sub4":              ar.Sub4,
-		"sub5":              ar.Sub5,
-		"userGroup":         ar.UserGroup,
-		"mediaSourceName":   ar.MediaSourceName,
-		"mediaSourceId":     ar.MediaSourceID,
-		"mediaSubSourceId":  ar.MediaSubSourceID,
-		"mediaAdsetName":    ar.MediaAdsetName,
-		"mediaAdsetId":      ar.MediaAdsetID,
-		"mediaCreativeName": ar.MediaCreativeName,
-		"mediaCreativeId":   ar.MediaCreativeID,
-		"mediaCampaignName": ar.MediaCampaignName,
+	errs = append(errs, ar.consentErrors()...)
+	errs = append(errs, ar.extraErrors()...)
+	if ar.PrivacyMode != "" && !ar.PrivacyMode.IsValid() {
+		errs = append(errs, &FieldError{Field: "PrivacyMode", JSONKey: "privacyMode", Rule: RuleOneOf, Message: "privacy mode must be raw, hash or omit"})
+	}
+	for _, f := range ar.freeTextFields() {
+		value := *f.addr
+		if value == nil {
+			continue
+		}
+		if utf8.RuneCountInString(*value) > MaxFieldLength {
+			errs = append(errs, &FieldError{Field: f.name, JSONKey: f.key, Rule: RuleMaxLength, Message: fmt.Sprintf("%s must be at most %d characters", f.key, MaxFieldLength)})
+		}
+		if hasControlChars(*value) {
+			errs = append(errs, controlCharsError(f.name, f.key))
+		}
+	}
+	return errs
+}
+
+// extraErrors reports Extra keys that are empty or collide with a known payload key, in key order.
+func (ar *AuthenticationRequest) extraErrors() ValidationErrors {
+	keys := make([]string, 0, len(ar.Extra))
+	for key := range ar.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs ValidationErrors
+	for _, key := range keys {
+		if key == "" {
+			errs = append(errs, &FieldError{Field: "Extra", Rule: RuleRequired, Message: "extra parameter key cannot be empty"})
+		} else if ar.isKnownKey(key) {
+			errs = append(errs, &FieldError{Field: "Extra", JSONKey: key, Rule: RuleReservedKey, Message: fmt.Sprintf("extra parameter %q collides with a known field", key)})
+		} else if _, err := json.Marshal(ar.Extra[key]); err != nil {
+			errs = append(errs, &FieldError{Field: "Extra", JSONKey: key, Rule: RuleFormat, Message: fmt.Sprintf("extra parameter %q cannot be encoded as JSON", key)})
+		}
 	}
-	for field, value := range stringFields {
-		if value != nil && fmt.Sprintf("%T", value) != "*string" {
-			return fmt.Errorf("%s must be a string", field)
+	return errs
+}
+
+type textField struct {
+	name string
+	key  string
+	addr **string
+}
+
+// freeTextFields returns the tracking fields that accept arbitrary text, in payload order.
+func (ar *AuthenticationRequest) freeTextFields() []textField {
+	return []textField{
+		{"Sub1", "sub1", &ar.Sub1},
+		{"Sub2", "sub2", &ar.Sub2},
+		{"Sub3", "sub3", &ar.Sub3},
+		{"Sub4", "sub4", &ar.Sub4},
+		{"Sub5", "sub5", &ar.Sub5},
+		{"UserGroup", "userGroup", &ar.UserGroup},
+		{"MediaSourceName", "mediaSourceName", &ar.MediaSourceName},
+		{"MediaSourceID", "mediaSourceId", &ar.MediaSourceID},
+		{"MediaSubSourceID", "mediaSubSourceId", &ar.MediaSubSourceID},
+		{"MediaAdsetName", "mediaAdsetName", &ar.MediaAdsetName},
+		{"MediaAdsetID", "mediaAdsetId", &ar.MediaAdsetID},
+		{"MediaCreativeName", "mediaCreativeName", &ar.MediaCreativeName},
+		{"MediaCreativeID", "mediaCreativeId", &ar.MediaCreativeID},
+		{"MediaCampaignName", "mediaCampaignName", &ar.MediaCampaignName},
+	}
+}
+
+// textFieldByKey returns the address of the tracking field with the given payload key, or nil.
+func (ar *AuthenticationRequest) textFieldByKey(key string) **string {
+	for _, f := range ar.freeTextFields() {
+		if f.key == key {
+			return f.addr
 		}
 	}
-	if ar.Incentivized != nil && fmt.Sprintf("%T", ar.Incentivized) != "*bool" {
-		return errors.New("incentivized must be a boolean")
+	return nil
+}
+
+func hasControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+func controlCharsError(field, key string) *FieldError {
+	return &FieldError{Field: field, JSONKey: key, Rule: RuleNoControlChars, Message: key + " must not contain control characters"}
+}
+
+func validateAge(age int) *FieldError {
+	if age < 0 {
+		return &FieldError{Field: "Age", JSONKey: "age", Rule: RuleMin, Message: "age must be a non-negative integer"}
+	}
+	if age > MaxAge {
+		return &FieldError{Field: "Age", JSONKey: "age", Rule: RuleMax, Message: fmt.Sprintf("age must be at most %d", MaxAge)}
 	}
 	return nil
 }
 
+// hasGender reports whether a gender is set. GenderUnspecified counts as absent, like in
+// WithGender, so it is neither validated nor sent.
+func (ar *AuthenticationRequest) hasGender() bool {
+	return ar.Gender != nil && *ar.Gender != enum.GenderUnspecified
+}
+
+func validateGender(gender enum.Gender) *FieldError {
+	if !gender.IsValid() {
+		return &FieldError{Field: "Gender", JSONKey: "gender", Rule: RuleOneOf, Message: "gender must be either 1 (male) or 2 (female)"}
+	}
+	return nil
+}
+
+func validateEmail(email string) *FieldError {
+	if !emailRegex.MatchString(email) {
+		return &FieldError{Field: "Email", JSONKey: "email", Rule: RuleFormat, Message: "invalid email format"}
+	}
+	return nil
+}
+
+// validatePhoneNumber checks phone as NormalizePhoneNumber does without a default country code:
+// the characters of every number, and the length of numbers with a country code.
+func validatePhoneNumber(phone string) *FieldError {
+	_, err := normalizePhoneNumber(phone, "")
+	return err
+}
+
+// DeriveAge sets Age from BirthDate as of now. An Age that is already set is kept, and a
+// birth date after now is left for validation to report.
+func (ar *AuthenticationRequest) DeriveAge(now time.Time) {
+	if ar.Age == nil && ar.BirthDate != nil && !ar.BirthDate.After(now) {
+		age := AgeOn(*ar.BirthDate, now)
+		ar.Age = &age
+	}
+}
+
+// AgeOn returns the age in completed years of someone born on birthDate, as of now.
+// The birth date is read as a UTC calendar date, as in Fingerprint, and compared with the
+// calendar date of now in its own location.
+func AgeOn(birthDate, now time.Time) int {
+	birthYear, birthMonth, birthDay := birthDate.UTC().Date()
+	year, month, day := now.Date()
+	age := year - birthYear
+	if month < birthMonth || (month == birthMonth && day < birthDay) {
+		age--
+	}
+	return age
+}
+
 // GetParsedAuthenticationRequestData returns a map containing the authentication request data.
-// Only includes fields that are defined and non-empty.
+// Only includes fields that are defined and non-empty, followed by the Extra parameters that do
+// not collide with a known key.
 func (ar *AuthenticationRequest) GetParsedAuthenticationRequestData() map[string]interface{} {
 	data := map[string]interface{}{
 		"publisherUserId": ar.PublisherUserID,
 	}
-	optionalFields := map[string]interface{}{
+	optionalFields := ar.optionalFields()
+	for key, value := range optionalFields {
+		switch v := value.(type) {
+		case *string:
+			if v != nil && *v != "" {
+				data[key] = *v
+			}
+		case *bool:
+			if v != nil {
+				data[key] = *v
+			}
+		case *int:
+			if v != nil {
+				data[key] = *v
+			}
+		case *enum.Gender:
+			if v != nil && *v != enum.GenderUnspecified {
+				data[key] = int(*v)
+			}
+		}
+	}
+	for key, value := range ar.Extra {
+		if !ar.isKnownKey(key) {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// isKnownKey reports whether key is the JSON key of a field, and so cannot be used in Extra.
+func (ar *AuthenticationRequest) isKnownKey(key string) bool {
+	if _, ok := ar.optionalFields()[key]; ok {
+		return true
+	}
+	return key == "publisherUserId" || key == jsonKeyBirthDate || key == jsonKeyPrivacyMode
+}
+
+// optionalFields maps the payload key of every optional field to a pointer to its value.
+func (ar *AuthenticationRequest) optionalFields() map[string]interface{} {
+	return map[string]interface{}{
 		"age":               ar.Age,
 		"gender":            ar.Gender,
 		"email":             ar.Email,
@@ -122,22 +336,11 @@ func (ar *AuthenticationRequest) GetParsedAuthenticationRequestData() map[string
 		"mediaCreativeName": ar.MediaCreativeName,
 		"mediaCreativeId":   ar.MediaCreativeID,
 		"mediaCampaignName": ar.MediaCampaignName,
+		"gdprApplies":       ar.GdprApplies,
+		"tcfConsent":        ar.TcfConsent,
+		"usPrivacy":         ar.UsPrivacy,
+		"limitAdTracking":   ar.LimitAdTracking,
+		"childDirected":     ar.ChildDirected,
+		"region":            ar.Region,
 	}
-	for key, value := range optionalFields {
-		switch v := value.(type) {
-		case *string:
-			if v != nil && *v != "" {
-				data[key] = *v
-			}
-		case *bool:
-			if v != nil {
-				data[key] = *v
-			}
-		case *int:
-			if v != nil {
-				data[key] = *v
-			}
-		}
-	}
-	return data
 }
@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"maps"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// AuthenticationRequestBuilder builds an AuthenticationRequest field by field. Errors that
+// leave no trace in the request, such as an invalid Sub index, are recorded as each setter or
+// option is applied; the fields are validated as a whole by Err and Build, which report every
+// violation at once.
+type AuthenticationRequestBuilder struct {
+	request AuthenticationRequest
+
+	// Now returns the current time, against which Err and Build check the birth date.
+	// Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewAuthenticationRequestBuilder creates a builder for the given publisher user ID.
+func NewAuthenticationRequestBuilder(publisherUserID string) *AuthenticationRequestBuilder {
+	return &AuthenticationRequestBuilder{
+		request: AuthenticationRequest{PublisherUserID: publisherUserID},
+		Now:     time.Now,
+	}
+}
+
+// Apply applies options to the request being built.
+func (b *AuthenticationRequestBuilder) Apply(opts ...AuthenticationRequestOptions) *AuthenticationRequestBuilder {
+	for _, opt := range opts {
+		opt(&b.request)
+	}
+	return b
+}
+
+// Age sets the user age.
+func (b *AuthenticationRequestBuilder) Age(age int) *AuthenticationRequestBuilder {
+	return b.Apply(WithAge(age))
+}
+
+// Gender sets the user gender. GenderUnspecified clears it.
+func (b *AuthenticationRequestBuilder) Gender(gender enum.Gender) *AuthenticationRequestBuilder {
+	return b.Apply(WithGender(gender))
+}
+
+// BirthDate sets the user birth date, from which Age is derived unless Age is also set.
+func (b *AuthenticationRequestBuilder) BirthDate(birthDate time.Time) *AuthenticationRequestBuilder {
+	return b.Apply(WithBirthDate(birthDate))
+}
+
+// GdprConsent marks the user as subject to GDPR and sets the TCF v2 consent string.
+func (b *AuthenticationRequestBuilder) GdprConsent(tcfConsent string) *AuthenticationRequestBuilder {
+	return b.Apply(WithGdprConsent(tcfConsent))
+}
+
+// UsPrivacy sets the US privacy (CCPA) string, such as "1YNN".
+func (b *AuthenticationRequestBuilder) UsPrivacy(usPrivacy string) *AuthenticationRequestBuilder {
+	return b.Apply(WithUsPrivacy(usPrivacy))
+}
+
+// LimitAdTracking sets whether the user opted out of ad tracking.
+func (b *AuthenticationRequestBuilder) LimitAdTracking(limit bool) *AuthenticationRequestBuilder {
+	return b.Apply(WithLimitAdTracking(limit))
+}
+
+// ChildDirected sets whether the user is subject to COPPA.
+func (b *AuthenticationRequestBuilder) ChildDirected(childDirected bool) *AuthenticationRequestBuilder {
+	return b.Apply(WithChildDirected(childDirected))
+}
+
+// Region sets the ISO 3166 code of the user's region, such as "DE" or "US-CA".
+func (b *AuthenticationRequestBuilder) Region(region string) *AuthenticationRequestBuilder {
+	return b.Apply(WithRegion(region))
+}
+
+// ExtraParam adds a parameter to the payload. A key colliding with a known field is reported by Err and Build.
+func (b *AuthenticationRequestBuilder) ExtraParam(key string, value any) *AuthenticationRequestBuilder {
+	return b.Apply(WithExtraParam(key, value))
+}
+
+// PrivacyMode overrides the SDK privacy mode for this request.
+func (b *AuthenticationRequestBuilder) PrivacyMode(mode enum.PrivacyMode) *AuthenticationRequestBuilder {
+	return b.Apply(WithPrivacyMode(mode))
+}
+
+// Email sets the user email.
+func (b *AuthenticationRequestBuilder) Email(email string) *AuthenticationRequestBuilder {
+	return b.Apply(WithEmail(email))
+}
+
+// PhoneNumber sets the user phone number.
+func (b *AuthenticationRequestBuilder) PhoneNumber(phone string) *AuthenticationRequestBuilder {
+	return b.Apply(WithPhoneNumber(phone))
+}
+
+// Sub sets the sub parameter n. An n outside 1 to 5 is reported by Err and Build.
+func (b *AuthenticationRequestBuilder) Sub(n int, value string) *AuthenticationRequestBuilder {
+	return b.Apply(WithSub(n, value))
+}
+
+// UserGroup sets the user group.
+func (b *AuthenticationRequestBuilder) UserGroup(group string) *AuthenticationRequestBuilder {
+	return b.Apply(WithUserGroup(group))
+}
+
+// MediaSource sets the media source name and ID.
+func (b *AuthenticationRequestBuilder) MediaSource(name, id string) *AuthenticationRequestBuilder {
+	return b.Apply(WithMediaSource(name, id))
+}
+
+// MediaSubSourceID sets the media sub-source ID.
+func (b *AuthenticationRequestBuilder) MediaSubSourceID(id string) *AuthenticationRequestBuilder {
+	return b.Apply(WithMediaSubSourceID(id))
+}
+
+// MediaAdset sets the media adset name and ID.
+func (b *AuthenticationRequestBuilder) MediaAdset(name, id string) *AuthenticationRequestBuilder {
+	return b.Apply(WithMediaAdset(name, id))
+}
+
+// MediaCreative sets the media creative name and ID.
+func (b *AuthenticationRequestBuilder) MediaCreative(name, id string) *AuthenticationRequestBuilder {
+	return b.Apply(WithMediaCreative(name, id))
+}
+
+// MediaCampaignName sets the media campaign name.
+func (b *AuthenticationRequestBuilder) MediaCampaignName(name string) *AuthenticationRequestBuilder {
+	return b.Apply(WithMediaCampaignName(name))
+}
+
+// Incentivized marks whether the user arrives from an incentivized placement.
+func (b *AuthenticationRequestBuilder) Incentivized(incentivized bool) *AuthenticationRequestBuilder {
+	return b.Apply(WithIncentivized(incentivized))
+}
+
+// Err validates the values set so far as of b.Now and returns every violation as
+// ValidationErrors, or nil. A field value corrected by a later setter no longer counts; an
+// error recorded while applying a setter or option always does.
+func (b *AuthenticationRequestBuilder) Err() error {
+	return b.request.validationErrors(b.Now()).err()
+}
+
+// Build returns a copy of the request, or nil and every violation found. The copy shares no
+// state with the builder, so later setters do not change it.
+func (b *AuthenticationRequestBuilder) Build() (*AuthenticationRequest, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	request := b.request
+	request.Extra = maps.Clone(b.request.Extra)
+	return &request, nil
+}
@@ -0,0 +1,120 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+func TestAuthenticationRequestBuilder_Build(t *testing.T) {
+	req, err := NewAuthenticationRequestBuilder("user123").
+		Age(25).
+		Gender(enum.GenderMale).
+		Email("test@example.com").
+		Sub(2, "campaign").
+		MediaSource("google", "g-1").
+		Incentivized(false).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.PublisherUserID != "user123" || *req.Age != 25 || *req.Gender != 1 || *req.Email != "test@example.com" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if *req.Sub2 != "campaign" || *req.MediaSourceName != "google" || *req.MediaSourceID != "g-1" || *req.Incentivized {
+		t.Errorf("unexpected request: %+v", req)
+	}
+}
+
+func TestAuthenticationRequestBuilder_ReportsAllErrors(t *testing.T) {
+	b := NewAuthenticationRequestBuilder("").
+		Age(-1).
+		Gender(3).
+		Email("invalid").
+		PhoneNumber("abc").
+		Sub(7, "x")
+
+	req, err := b.Build()
+	if req != nil {
+		t.Errorf("expected nil request, got %+v", req)
+	}
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	for _, msg := range []string{
+		"sub index must be between 1 and 5, got 7",
+		"publisher user ID cannot be empty and must be a string",
+		"age must be a non-negative integer",
+		"gender must be either 1 (male) or 2 (female)",
+		"invalid email format",
+		"invalid phone number format",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error to contain %q, got %q", msg, err.Error())
+		}
+	}
+}
+
+func TestAuthenticationRequestBuilder_Err(t *testing.T) {
+	b := NewAuthenticationRequestBuilder("user123")
+	if err := b.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.Email("invalid")
+	if err := b.Err(); err == nil || err.Error() != "invalid email format" {
+		t.Errorf("expected email error, got %v", err)
+	}
+
+	b.Email("test@example.com")
+	if err := b.Err(); err != nil {
+		t.Errorf("expected corrected value to clear the error, got %v", err)
+	}
+}
+
+func TestAuthenticationRequestBuilder_BuildReturnsCopy(t *testing.T) {
+	b := NewAuthenticationRequestBuilder("user123").Apply(WithUserGroup("a"))
+	first, _ := b.Build()
+	b.UserGroup("b")
+	second, _ := b.Build()
+
+	if *first.UserGroup != "a" || *second.UserGroup != "b" {
+		t.Errorf("expected independent requests, got %s and %s", *first.UserGroup, *second.UserGroup)
+	}
+}
+
+func TestAuthenticationRequestBuilder_RecordsOptionErrors(t *testing.T) {
+	b := NewAuthenticationRequestBuilder("user123").Apply(WithSub(9, "x"))
+	b.Sub(1, "y")
+
+	if err := b.Err(); err == nil || err.Error() != "sub index must be between 1 and 5, got 9" {
+		t.Errorf("expected the sub index error recorded by Apply to remain, got %v", err)
+	}
+}
+
+func TestAuthenticationRequestBuilder_Now(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewAuthenticationRequestBuilder("user123").BirthDate(now.AddDate(1, 0, 0))
+	if err := b.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.Now = func() time.Time { return now }
+	if err := b.Err(); err == nil || err.Error() != "birth date must not be in the future" {
+		t.Errorf("expected the birth date to be checked against Now, got %v", err)
+	}
+}
+
+func TestAuthenticationRequestBuilder_BuildCopiesExtra(t *testing.T) {
+	b := NewAuthenticationRequestBuilder("user123").ExtraParam("a", 1)
+	first, _ := b.Build()
+	b.ExtraParam("b", 2)
+
+	if len(first.Extra) != 1 {
+		t.Errorf("expected the built request to keep its own Extra, got %v", first.Extra)
+	}
+}
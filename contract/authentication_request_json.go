@@ -0,0 +1,152 @@
+package contract
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// JSON keys of the fields that are stored with a request but not sent to the API.
+const (
+	jsonKeyBirthDate   = "birthDate"
+	jsonKeyPrivacyMode = "privacyMode"
+)
+
+// MarshalJSON encodes the request with the fields of GetParsedAuthenticationRequestData, so
+// zero numbers and false are kept while empty strings and GenderUnspecified are left out like
+// missing fields, plus birthDate and privacyMode when set. The result can be stored or queued
+// and decoded with UnmarshalJSON into a request that sends the same payload.
+func (ar AuthenticationRequest) MarshalJSON() ([]byte, error) {
+	plain := plainAuthenticationRequest(ar)
+	if !ar.hasGender() {
+		plain.Gender = nil
+	}
+	encoded, err := json.Marshal(plain)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	data := make(map[string]any, len(fields)+len(ar.Extra)+2)
+	for key, value := range fields {
+		if string(value) != `""` {
+			data[key] = value
+		}
+	}
+
+	for key, value := range ar.Extra {
+		if !ar.isKnownKey(key) {
+			data[key] = value
+		}
+	}
+	if ar.BirthDate != nil {
+		data[jsonKeyBirthDate] = *ar.BirthDate
+	}
+	if ar.PrivacyMode != "" {
+		data[jsonKeyPrivacyMode] = ar.PrivacyMode
+	}
+	return json.Marshal(data)
+}
+
+// plainAuthenticationRequest has the fields of AuthenticationRequest without its methods.
+type plainAuthenticationRequest AuthenticationRequest
+
+// UnmarshalJSON decodes a request encoded by MarshalJSON. Keys that are not fields of
+// the request are kept in Extra, with numbers decoded as json.Number so they are sent unchanged.
+func (ar *AuthenticationRequest) UnmarshalJSON(data []byte) error {
+	var decoded plainAuthenticationRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if value, ok := raw[jsonKeyBirthDate]; ok {
+		var birthDate time.Time
+		if err := json.Unmarshal(value, &birthDate); err != nil {
+			return err
+		}
+		decoded.BirthDate = &birthDate
+	}
+	if value, ok := raw[jsonKeyPrivacyMode]; ok {
+		var mode enum.PrivacyMode
+		if err := json.Unmarshal(value, &mode); err != nil {
+			return err
+		}
+		decoded.PrivacyMode = mode
+	}
+
+	request := AuthenticationRequest(decoded)
+	if !request.hasGender() {
+		request.Gender = nil
+	}
+	for key, value := range raw {
+		if request.isKnownKey(key) {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		var extra any
+		if err := decoder.Decode(&extra); err != nil {
+			return err
+		}
+		if request.Extra == nil {
+			request.Extra = map[string]any{}
+		}
+		request.Extra[key] = extra
+	}
+
+	*ar = request
+	return nil
+}
+
+// CanonicalPayload returns the payload of GetParsedAuthenticationRequestData as JSON with
+// sorted keys, as the SDK sends it before applying its privacy and consent policies, plus
+// birthDate as a UTC date such as "1990-05-01" when set. Equal requests always produce equal
+// bytes, and birth dates on the same UTC day are equal whatever their time and location.
+func (ar *AuthenticationRequest) CanonicalPayload() ([]byte, error) {
+	return json.Marshal(ar.canonicalData())
+}
+
+// Fingerprint returns the hex SHA-256 of CanonicalPayload after applying the request's
+// PrivacyMode to the personal data and the birth date, so with enum.PrivacyModeHash no raw
+// personal data is hashed. It covers the publisher user ID, every payload field, the Extra
+// parameters and the birth date, but not the SDK consent and age policies or the user ID
+// mapping; TyrAdsSdk.Fingerprint covers the payload after all of them. It is suitable as a
+// cache or idempotency key.
+func (ar *AuthenticationRequest) Fingerprint() (string, error) {
+	data := ar.canonicalData()
+	ApplyPrivacyMode(data, ar.PrivacyMode)
+	if birthDate, ok := data[jsonKeyBirthDate].(string); ok {
+		switch ar.PrivacyMode {
+		case "", enum.PrivacyModeRaw:
+		case enum.PrivacyModeHash:
+			data[jsonKeyBirthDate] = HashPersonalData(birthDate)
+		default:
+			delete(data, jsonKeyBirthDate)
+		}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalData returns the payload with the birth date as a UTC date.
+func (ar *AuthenticationRequest) canonicalData() map[string]interface{} {
+	data := ar.GetParsedAuthenticationRequestData()
+	if ar.BirthDate != nil {
+		data[jsonKeyBirthDate] = ar.BirthDate.UTC().Format(time.DateOnly)
+	}
+	return data
+}
@@ -0,0 +1,277 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+func TestAuthenticationRequest_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *AuthenticationRequest
+		expected string
+	}{
+		{
+			name:     "publisher user ID only",
+			request:  NewAuthenticationRequest("user123"),
+			expected: `{"publisherUserId":"user123"}`,
+		},
+		{
+			name:     "zero values kept and empty strings left out",
+			request:  NewAuthenticationRequest("user123", WithAge(0), WithSub(1, ""), WithIncentivized(false)),
+			expected: `{"age":0,"incentivized":false,"publisherUserId":"user123"}`,
+		},
+		{
+			name:     "unspecified gender left out",
+			request:  &AuthenticationRequest{PublisherUserID: "user123", Gender: genderPtr(enum.GenderUnspecified)},
+			expected: `{"publisherUserId":"user123"}`,
+		},
+		{
+			name:     "gender as number and extra params",
+			request:  NewAuthenticationRequest("user123", WithGender(enum.GenderFemale), WithExtraParam("newField", 1.5)),
+			expected: `{"gender":2,"newField":1.5,"publisherUserId":"user123"}`,
+		},
+		{
+			name: "stored fields",
+			request: NewAuthenticationRequest("user123",
+				WithBirthDate(time.Date(1990, time.May, 4, 0, 0, 0, 0, time.UTC)),
+				WithPrivacyMode(enum.PrivacyModeHash),
+			),
+			expected: `{"birthDate":"1990-05-04T00:00:00Z","privacyMode":"hash","publisherUserId":"user123"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestAuthenticationRequest_JSONRoundTrip(t *testing.T) {
+	original := NewAuthenticationRequest("user123",
+		WithAge(0),
+		WithGender(enum.GenderMale),
+		WithEmail("test@example.com"),
+		WithSub(2, "campaign"),
+		WithMediaSource("google", "g-1"),
+		WithIncentivized(true),
+		WithGdprConsent("CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"),
+		WithBirthDate(time.Date(1990, time.May, 4, 0, 0, 0, 0, time.FixedZone("CET", 3600))),
+		WithPrivacyMode(enum.PrivacyModeOmit),
+		WithExtraParam("score", 12345678901),
+		WithExtraParam("tags", []string{"a", "b"}),
+	)
+
+	stored, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var replayed AuthenticationRequest
+	if err := json.Unmarshal(stored, &replayed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !replayed.BirthDate.Equal(*original.BirthDate) || replayed.PrivacyMode != enum.PrivacyModeOmit {
+		t.Errorf("expected stored fields to survive, got %v and %s", replayed.BirthDate, replayed.PrivacyMode)
+	}
+	if !reflect.DeepEqual(replayed.Gender, original.Gender) || *replayed.Age != 0 {
+		t.Errorf("expected typed fields to survive, got %+v", replayed)
+	}
+
+	sent, _ := json.Marshal(original.GetParsedAuthenticationRequestData())
+	resent, _ := json.Marshal(replayed.GetParsedAuthenticationRequestData())
+	if string(sent) != string(resent) {
+		t.Errorf("expected identical payloads, got\n%s\n%s", sent, resent)
+	}
+
+	restored, _ := json.Marshal(replayed)
+	if string(stored) != string(restored) {
+		t.Errorf("expected identical encodings, got\n%s\n%s", stored, restored)
+	}
+}
+
+func TestAuthenticationRequest_JSONRoundTripKeepsZeroValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *AuthenticationRequest
+	}{
+		{name: "publisher user ID only", request: NewAuthenticationRequest("user123")},
+		{name: "zero values", request: NewAuthenticationRequest("user123", WithAge(0), WithIncentivized(false), WithLimitAdTracking(false))},
+		{
+			name: "every field",
+			request: NewAuthenticationRequest("user123",
+				WithAge(30),
+				WithGender(enum.GenderFemale),
+				WithEmail("a@b.co"),
+				WithPhoneNumber("+14155550123"),
+				WithSub(5, "s5"),
+				WithUserGroup("vip"),
+				WithMediaAdset("adset", "a-1"),
+				WithMediaCreative("creative", "c-1"),
+				WithMediaCampaignName("campaign"),
+				WithMediaSubSourceID("sub-1"),
+				WithUsPrivacy("1YNN"),
+				WithChildDirected(false),
+				WithRegion("US-CA"),
+				WithBirthDate(time.Date(1994, time.July, 1, 0, 0, 0, 0, time.UTC)),
+				WithPrivacyMode(enum.PrivacyModeHash),
+				WithExtraParam("label", "x"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decoded := decodeRequest(t, tt.request); !reflect.DeepEqual(decoded, tt.request) {
+				t.Errorf("expected %+v, got %+v", tt.request, decoded)
+			}
+		})
+	}
+}
+
+func TestAuthenticationRequest_MarshalJSONMatchesPayload(t *testing.T) {
+	req := NewAuthenticationRequest("user123",
+		WithAge(0),
+		WithGender(enum.GenderUnspecified),
+		WithEmail(""),
+		WithSub(1, ""),
+		WithSub(2, "x"),
+		WithMediaSource("", "m-1"),
+		WithIncentivized(false),
+		WithLimitAdTracking(false),
+		WithRegion(""),
+		WithExtraParam("label", ""),
+	)
+
+	stored, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent, _ := json.Marshal(req.GetParsedAuthenticationRequestData())
+	if string(stored) != string(sent) {
+		t.Errorf("expected MarshalJSON to match the payload, got\n%s\n%s", stored, sent)
+	}
+}
+
+func TestAuthenticationRequest_UnmarshalJSON(t *testing.T) {
+	var req AuthenticationRequest
+	err := json.Unmarshal([]byte(`{"publisherUserId":"user123","gender":"f","age":30,"futureField":{"nested":true}}`), &req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.PublisherUserID != "user123" || *req.Gender != enum.GenderFemale || *req.Age != 30 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if expected := map[string]any{"futureField": map[string]any{"nested": true}}; !reflect.DeepEqual(req.Extra, expected) {
+		t.Errorf("expected unknown keys in Extra, got %v", req.Extra)
+	}
+
+	if err := json.Unmarshal([]byte(`{"publisherUserId":"user123","gender":"other"}`), &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Gender != nil || req.ValidateAuthenticationRequest() != nil {
+		t.Errorf("expected unspecified gender to be absent, got %v", req.Gender)
+	}
+
+	for _, input := range []string{`[]`, `{"age":"thirty"}`, `{"birthDate":"yesterday"}`} {
+		if err := json.Unmarshal([]byte(input), &req); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+func TestAuthenticationRequest_CanonicalPayload(t *testing.T) {
+	a := NewAuthenticationRequest("user123", WithSub(1, "s1"), WithAge(25), WithExtraParam("ratio", 0.5), WithEmail("a@b.co"))
+	b := NewAuthenticationRequest("user123", WithEmail("a@b.co"), WithExtraParam("ratio", 0.5), WithAge(25), WithSub(1, "s1"))
+
+	first, err := a.CanonicalPayload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, _ := b.CanonicalPayload()
+		if string(again) != string(first) {
+			t.Fatalf("expected stable payload, got %s and %s", first, again)
+		}
+	}
+	if expected := `{"age":25,"email":"a@b.co","publisherUserId":"user123","ratio":0.5,"sub1":"s1"}`; string(first) != expected {
+		t.Errorf("expected %s, got %s", expected, first)
+	}
+
+	east := time.FixedZone("UTC+2", 2*60*60)
+	morning, _ := NewAuthenticationRequest("user123", WithBirthDate(time.Date(1990, time.May, 1, 9, 0, 0, 0, east))).CanonicalPayload()
+	evening, _ := NewAuthenticationRequest("user123", WithBirthDate(time.Date(1990, time.May, 1, 23, 30, 0, 0, time.UTC))).CanonicalPayload()
+	if expected := `{"birthDate":"1990-05-01","publisherUserId":"user123"}`; string(morning) != expected || string(evening) != expected {
+		t.Errorf("expected %s, got %s and %s", expected, morning, evening)
+	}
+}
+
+func TestAuthenticationRequest_Fingerprint(t *testing.T) {
+	fingerprint := func(req *AuthenticationRequest) string {
+		t.Helper()
+		f, err := req.Fingerprint()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return f
+	}
+
+	base := fingerprint(NewAuthenticationRequest("user123", WithAge(25), WithSub(2, "x"), WithEmail("a@b.co")))
+	if len(base) != 64 {
+		t.Errorf("expected hex SHA-256, got %s", base)
+	}
+
+	tests := []struct {
+		name     string
+		request  *AuthenticationRequest
+		expected bool
+	}{
+		{name: "same fields in another order", request: NewAuthenticationRequest("user123", WithEmail("a@b.co"), WithSub(2, "x"), WithAge(25)), expected: true},
+		{name: "empty string is not sent", request: NewAuthenticationRequest("user123", WithAge(25), WithSub(2, "x"), WithEmail("a@b.co"), WithSub(3, "")), expected: true},
+		{name: "stored decoded copy", request: decodeRequest(t, NewAuthenticationRequest("user123", WithAge(25), WithSub(2, "x"), WithEmail("a@b.co"))), expected: true},
+		{name: "different value", request: NewAuthenticationRequest("user123", WithAge(26), WithSub(2, "x"), WithEmail("a@b.co"))},
+		{name: "hashed personal data", request: NewAuthenticationRequest("user123", WithAge(25), WithSub(2, "x"), WithEmail("a@b.co"), WithPrivacyMode(enum.PrivacyModeHash))},
+		{name: "birth date", request: NewAuthenticationRequest("user123", WithAge(25), WithSub(2, "x"), WithEmail("a@b.co"), WithBirthDate(time.Date(1990, time.May, 1, 0, 0, 0, 0, time.UTC)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.request) == base; got != tt.expected {
+				t.Errorf("expected equal fingerprints %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	hashed := fingerprint(NewAuthenticationRequest("user123", WithEmail("a@b.co"), WithPrivacyMode(enum.PrivacyModeHash)))
+	if expected := fingerprint(NewAuthenticationRequest("user123", WithEmail(HashPersonalData("a@b.co")))); hashed != expected {
+		t.Errorf("expected the fingerprint of the hashed payload %s, got %s", expected, hashed)
+	}
+
+	if _, err := NewAuthenticationRequest("user123", WithExtraParam("bad", func() {})).Fingerprint(); err == nil {
+		t.Error("expected error for an extra value that cannot be encoded")
+	}
+}
+
+func decodeRequest(t *testing.T, req *AuthenticationRequest) *AuthenticationRequest {
+	t.Helper()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded AuthenticationRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &decoded
+}
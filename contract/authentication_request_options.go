@@ -0,0 +1,186 @@
+package contract
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// WithAge sets the user age.
+func WithAge(age int) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.Age = &age
+	}
+}
+
+// WithGender sets the user gender. GenderUnspecified clears it, so the field is not sent.
+func WithGender(gender enum.Gender) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		if gender == enum.GenderUnspecified {
+			ar.Gender = nil
+			return
+		}
+		ar.Gender = &gender
+	}
+}
+
+// WithBirthDate sets the user birth date. The SDK derives Age from it when the request is sent,
+// unless WithAge is also used.
+func WithBirthDate(birthDate time.Time) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.BirthDate = &birthDate
+	}
+}
+
+// WithGdprConsent marks the user as subject to GDPR and sets the TCF v2 consent string.
+// An empty consent string means consent was not given.
+func WithGdprConsent(tcfConsent string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		applies := true
+		ar.GdprApplies = &applies
+		ar.TcfConsent = &tcfConsent
+	}
+}
+
+// WithUsPrivacy sets the US privacy (CCPA) string, such as "1YNN".
+func WithUsPrivacy(usPrivacy string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.UsPrivacy = &usPrivacy
+	}
+}
+
+// WithLimitAdTracking sets whether the user opted out of ad tracking.
+func WithLimitAdTracking(limit bool) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.LimitAdTracking = &limit
+	}
+}
+
+// WithChildDirected sets whether the user is subject to COPPA.
+func WithChildDirected(childDirected bool) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.ChildDirected = &childDirected
+	}
+}
+
+// WithRegion sets the ISO 3166 code of the user's region, such as "DE" or "US-CA".
+func WithRegion(region string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.Region = &region
+	}
+}
+
+// WithExtraParam adds a parameter to the payload, for API fields the SDK does not model yet.
+// A key that collides with a known field is reported by ValidateAuthenticationRequest.
+func WithExtraParam(key string, value any) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		if ar.Extra == nil {
+			ar.Extra = map[string]any{}
+		}
+		ar.Extra[key] = value
+	}
+}
+
+// WithPrivacyMode overrides the SDK privacy mode for this request.
+func WithPrivacyMode(mode enum.PrivacyMode) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.PrivacyMode = mode
+	}
+}
+
+// WithEmail sets the user email.
+func WithEmail(email string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.Email = &email
+	}
+}
+
+// WithPhoneNumber sets the user phone number.
+func WithPhoneNumber(phone string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.PhoneNumber = &phone
+	}
+}
+
+// WithSub sets the sub parameter n, from 1 to 5. Other values of n leave the fields unchanged
+// and are reported by ValidateAuthenticationRequest.
+func WithSub(n int, value string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		sub := ar.subField(n)
+		if sub == nil {
+			ar.optionErrs = append(ar.optionErrs, &FieldError{Field: "Sub", JSONKey: "sub", Rule: RuleRange, Message: fmt.Sprintf("sub index must be between 1 and 5, got %d", n)})
+			return
+		}
+		*sub = &value
+	}
+}
+
+// WithUserGroup sets the user group.
+func WithUserGroup(group string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.UserGroup = &group
+	}
+}
+
+// WithMediaSource sets the media source name and ID.
+func WithMediaSource(name, id string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.MediaSourceName = &name
+		ar.MediaSourceID = &id
+	}
+}
+
+// WithMediaSubSourceID sets the media sub-source ID.
+func WithMediaSubSourceID(id string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.MediaSubSourceID = &id
+	}
+}
+
+// WithMediaAdset sets the media adset name and ID.
+func WithMediaAdset(name, id string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.MediaAdsetName = &name
+		ar.MediaAdsetID = &id
+	}
+}
+
+// WithMediaCreative sets the media creative name and ID.
+func WithMediaCreative(name, id string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.MediaCreativeName = &name
+		ar.MediaCreativeID = &id
+	}
+}
+
+// WithMediaCampaignName sets the media campaign name.
+func WithMediaCampaignName(name string) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.MediaCampaignName = &name
+	}
+}
+
+// WithIncentivized marks whether the user arrives from an incentivized placement.
+func WithIncentivized(incentivized bool) AuthenticationRequestOptions {
+	return func(ar *AuthenticationRequest) {
+		ar.Incentivized = &incentivized
+	}
+}
+
+// subField returns the address of the sub parameter n, or nil when n is not between 1 and 5.
+func (ar *AuthenticationRequest) subField(n int) **string {
+	switch n {
+	case 1:
+		return &ar.Sub1
+	case 2:
+		return &ar.Sub2
+	case 3:
+		return &ar.Sub3
+	case 4:
+		return &ar.Sub4
+	case 5:
+		return &ar.Sub5
+	}
+	return nil
+}
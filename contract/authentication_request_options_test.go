@@ -0,0 +1,77 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+func TestAuthenticationRequestOptions(t *testing.T) {
+	req := NewAuthenticationRequest("user123",
+		WithAge(25),
+		WithGender(enum.GenderFemale),
+		WithEmail("test@example.com"),
+		WithPhoneNumber("+1234567890"),
+		WithSub(1, "s1"),
+		WithSub(5, "s5"),
+		WithUserGroup("vip"),
+		WithMediaSource("google", "g-1"),
+		WithMediaSubSourceID("sub-1"),
+		WithMediaAdset("adset", "a-1"),
+		WithMediaCreative("creative", "c-1"),
+		WithMediaCampaignName("campaign"),
+		WithIncentivized(true),
+	)
+
+	expected := map[string]interface{}{
+		"publisherUserId":   "user123",
+		"age":               25,
+		"gender":            2,
+		"email":             "test@example.com",
+		"phoneNumber":       "+1234567890",
+		"sub1":              "s1",
+		"sub5":              "s5",
+		"userGroup":         "vip",
+		"mediaSourceName":   "google",
+		"mediaSourceId":     "g-1",
+		"mediaSubSourceId":  "sub-1",
+		"mediaAdsetName":    "adset",
+		"mediaAdsetId":      "a-1",
+		"mediaCreativeName": "creative",
+		"mediaCreativeId":   "c-1",
+		"mediaCampaignName": "campaign",
+		"incentivized":      true,
+	}
+
+	if result := req.GetParsedAuthenticationRequestData(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestWithSub_OutOfRange(t *testing.T) {
+	req := NewAuthenticationRequest("user123", WithSub(0, "x"), WithSub(6, "y"))
+
+	if data := req.GetParsedAuthenticationRequestData(); !reflect.DeepEqual(data, map[string]interface{}{"publisherUserId": "user123"}) {
+		t.Errorf("expected out of range subs to leave the fields unchanged, got %+v", data)
+	}
+
+	var verrs ValidationErrors
+	if err := req.ValidateAuthenticationRequest(); !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Fatalf("expected two validation errors, got %v", err)
+	}
+	for i, n := range []int{0, 6} {
+		if expected := fmt.Sprintf("sub index must be between 1 and 5, got %d", n); verrs[i].Rule != RuleRange || verrs[i].Message != expected {
+			t.Errorf("expected %q, got %+v", expected, verrs[i])
+		}
+	}
+}
+
+func TestWithGender_Unspecified(t *testing.T) {
+	req := NewAuthenticationRequest("user123", WithGender(enum.GenderMale), WithGender(enum.GenderUnspecified))
+	if req.Gender != nil {
+		t.Errorf("expected GenderUnspecified to clear gender, got %v", *req.Gender)
+	}
+}
@@ -1,8 +1,12 @@
 package contract
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
 )
 
 func TestNewAuthenticationRequest(t *testing.T) {
@@ -10,8 +14,8 @@ func TestNewAuthenticationRequest(t *testing.T) {
 	phone := "+1234567890"
 	age25 := 25
 	age30 := 30
-	gender1 := 1
-	gender2 := 2
+	gender1 := enum.GenderMale
+	gender2 := enum.GenderFemale
 
 	tests := []struct {
 		name            string
@@ -74,6 +78,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 	invalidEmail := "invalid-email"
 	validPhone := "+1234567890"
 	invalidPhone := "invalid-phone"
+	shortPhone := "12-3"
 
 	tests := []struct {
 		name    string
@@ -86,7 +91,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 			},
 			wantErr: false,
 		},
@@ -102,7 +107,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 			},
 			wantErr: true,
 			errMsg:  "publisher user ID cannot be empty and must be a string",
@@ -112,7 +117,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := -1; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 			},
 			wantErr: true,
 			errMsg:  "age must be a non-negative integer",
@@ -122,17 +127,24 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 3; return &v }(),
+				Gender:          genderPtr(3),
 			},
 			wantErr: true,
 			errMsg:  "gender must be either 1 (male) or 2 (female)",
 		},
+		{
+			name: "unspecified gender is absent",
+			request: &AuthenticationRequest{
+				PublisherUserID: "user123",
+				Gender:          genderPtr(enum.GenderUnspecified),
+			},
+		},
 		{
 			name: "valid email",
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 				Email:           &validEmail,
 			},
 			wantErr: false,
@@ -142,7 +154,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 				Email:           &invalidEmail,
 			},
 			wantErr: true,
@@ -153,7 +165,7 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 				PhoneNumber:     &validPhone,
 			},
 			wantErr: false,
@@ -163,12 +175,21 @@ func TestValidateAuthenticationRequest(t *testing.T) {
 			request: &AuthenticationRequest{
 				PublisherUserID: "user123",
 				Age:             func() *int { v := 25; return &v }(),
-				Gender:          func() *int { v := 1; return &v }(),
+				Gender:          genderPtr(enum.GenderMale),
 				PhoneNumber:     &invalidPhone,
 			},
 			wantErr: true,
 			errMsg:  "invalid phone number format",
 		},
+		{
+			name: "short national phone",
+			request: &AuthenticationRequest{
+				PublisherUserID: "user123",
+				PhoneNumber:     &shortPhone,
+			},
+			wantErr: true,
+			errMsg:  "phone number must have 7 to 15 digits",
+		},
 	}
 
 	for _, tt := range tests {
@@ -200,7 +221,7 @@ func TestGetParsedAuthenticationRequestData(t *testing.T) {
 	request := &AuthenticationRequest{
 		PublisherUserID: "user123",
 		Age:             func() *int { v := 25; return &v }(),
-		Gender:          func() *int { v := 1; return &v }(),
+		Gender:          genderPtr(enum.GenderMale),
 		Email:           &email,
 		PhoneNumber:     &phone,
 		Sub1:            &sub1,
@@ -231,7 +252,7 @@ func TestGetParsedAuthenticationRequestData_SkipsNilAndEmptyFields(t *testing.T)
 	request := &AuthenticationRequest{
 		PublisherUserID: "user123",
 		Age:             func() *int { v := 25; return &v }(),
-		Gender:          func() *int { v := 1; return &v }(),
+		Gender:          genderPtr(enum.GenderMale),
 		Email:           &emptyString, // should be skipped
 		Sub1:            &sub1,        // should be included
 		Sub2:            nil,          // should be skipped
@@ -250,3 +271,173 @@ func TestGetParsedAuthenticationRequestData_SkipsNilAndEmptyFields(t *testing.T)
 		t.Errorf("expected %+v, got %+v", expected, result)
 	}
 }
+
+func TestGetParsedAuthenticationRequestData_SkipsUnspecifiedGender(t *testing.T) {
+	request := &AuthenticationRequest{PublisherUserID: "user123", Gender: genderPtr(enum.GenderUnspecified)}
+
+	expected := map[string]interface{}{"publisherUserId": "user123"}
+	if result := request.GetParsedAuthenticationRequestData(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+	if stripped := request.StripProfile(); len(stripped) != 0 {
+		t.Errorf("expected nothing stripped, got %v", stripped)
+	}
+}
+
+func genderPtr(g enum.Gender) *enum.Gender {
+	return &g
+}
+
+func TestAgeOn(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		expected  int
+	}{
+		{name: "birthday today", birthDate: time.Date(2000, time.March, 10, 0, 0, 0, 0, time.UTC), expected: 24},
+		{name: "birthday tomorrow", birthDate: time.Date(2000, time.March, 11, 0, 0, 0, 0, time.UTC), expected: 23},
+		{name: "birthday last month", birthDate: time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC), expected: 24},
+		{name: "birthday next month", birthDate: time.Date(2010, time.April, 1, 0, 0, 0, 0, time.UTC), expected: 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeOn(tt.birthDate, now); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAgeOn_NegativeOffset(t *testing.T) {
+	newYork := time.FixedZone("EST", -5*60*60)
+	birthDate := time.Date(2000, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected int
+	}{
+		{name: "evening before the birthday", now: time.Date(2024, time.March, 9, 22, 0, 0, 0, newYork), expected: 23},
+		{name: "morning of the birthday", now: time.Date(2024, time.March, 10, 8, 0, 0, 0, newYork), expected: 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeOn(birthDate, tt.now); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDeriveAge(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	birthDate := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	req := NewAuthenticationRequest("user123", WithBirthDate(birthDate))
+	req.DeriveAge(now)
+	if req.Age == nil || *req.Age != 34 {
+		t.Errorf("expected derived age 34, got %v", req.Age)
+	}
+
+	req = NewAuthenticationRequest("user123", WithAge(40), WithBirthDate(birthDate))
+	req.DeriveAge(now)
+	if *req.Age != 40 {
+		t.Errorf("expected explicit age to be kept, got %d", *req.Age)
+	}
+}
+
+func TestValidateAuthenticationRequest_AgeBounds(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		request      *AuthenticationRequest
+		now          time.Time
+		expectedRule string
+	}{
+		{name: "max age", request: NewAuthenticationRequest("user123", WithAge(MaxAge)), now: now},
+		{name: "above max age", request: NewAuthenticationRequest("user123", WithAge(500)), now: now, expectedRule: RuleMax},
+		{name: "future birth date", request: NewAuthenticationRequest("user123", WithBirthDate(now.AddDate(1, 0, 0))), now: now, expectedRule: RuleRange},
+		{name: "birth date in the past of now", request: NewAuthenticationRequest("user123", WithBirthDate(now.AddDate(-1, 0, 0))), now: now},
+		{name: "birth date after an earlier now", request: NewAuthenticationRequest("user123", WithBirthDate(now.AddDate(-1, 0, 0))), now: now.AddDate(-2, 0, 0), expectedRule: RuleRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.ValidateAuthenticationRequestAt(tt.now)
+			if tt.expectedRule == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Rule != tt.expectedRule {
+				t.Errorf("expected %s violation, got %v", tt.expectedRule, err)
+			}
+		})
+	}
+}
+
+func TestWithExtraParam(t *testing.T) {
+	req := NewAuthenticationRequest("user123",
+		WithAge(30),
+		WithExtraParam("newApiField", "value"),
+		WithExtraParam("score", 42),
+	)
+
+	if err := req.ValidateAuthenticationRequest(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"publisherUserId": "user123",
+		"age":             30,
+		"newApiField":     "value",
+		"score":           42,
+	}
+	if result := req.GetParsedAuthenticationRequestData(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestWithExtraParam_Collisions(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		expectedRule string
+	}{
+		{name: "known optional field", key: "email", expectedRule: RuleReservedKey},
+		{name: "unset known field", key: "sub3", expectedRule: RuleReservedKey},
+		{name: "publisher user ID", key: "publisherUserId", expectedRule: RuleReservedKey},
+		{name: "empty key", key: "", expectedRule: RuleRequired},
+		{name: "stored field", key: "birthDate", expectedRule: RuleReservedKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewAuthenticationRequest("user123", WithEmail("test@example.com"), WithExtraParam(tt.key, "override"))
+
+			v, ok := AsValidationErrors(req.ValidateAuthenticationRequest())
+			if !ok || len(v) != 1 || v[0].Field != "Extra" || v[0].Rule != tt.expectedRule {
+				t.Fatalf("expected one Extra %s violation, got %v", tt.expectedRule, v)
+			}
+
+			data := req.GetParsedAuthenticationRequestData()
+			if data["email"] != "test@example.com" || data["publisherUserId"] != "user123" {
+				t.Errorf("expected known fields not to be overridden, got %+v", data)
+			}
+		})
+	}
+}
+
+func TestWithExtraParam_Unencodable(t *testing.T) {
+	err := NewAuthenticationRequest("user123", WithExtraParam("callback", func() {})).ValidateAuthenticationRequest()
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.JSONKey != "callback" || fieldErr.Rule != RuleFormat {
+		t.Errorf("expected callback format violation, got %v", err)
+	}
+}
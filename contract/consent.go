@@ -0,0 +1,76 @@
+package contract
+
+import "regexp"
+
+var (
+	// A TCF v2 consent string is base64url segments separated by dots, starting with version "C".
+	tcfConsentRegex = regexp.MustCompile(`^C[A-Za-z0-9_-]*(\.[A-Za-z0-9_-]+)*$`)
+	// A US privacy (CCPA) string is version 1 followed by notice, opt-out and LSPA flags.
+	usPrivacyRegex = regexp.MustCompile(`^1[YN-][YN-][YN-]$`)
+	// A region is an ISO 3166-1 alpha-2 country code, optionally with an ISO 3166-2 subdivision.
+	regionRegex = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+)
+
+// ConsentPolicy adjusts a request according to its consent fields before it is sent,
+// and returns the payload keys it removed.
+type ConsentPolicy func(ar *AuthenticationRequest) []string
+
+// DefaultConsentPolicy strips the user profile when HasProfileConsent reports false.
+func DefaultConsentPolicy(ar *AuthenticationRequest) []string {
+	if ar.HasProfileConsent() {
+		return nil
+	}
+	return ar.StripProfile()
+}
+
+// HasProfileConsent reports whether the profile fields may be sent. It is false when the
+// user is child-directed, limits ad tracking, opted out of sale under the US privacy string,
+// or is subject to GDPR without a TCF consent string.
+func (ar *AuthenticationRequest) HasProfileConsent() bool {
+	switch {
+	case ar.ChildDirected != nil && *ar.ChildDirected:
+		return false
+	case ar.LimitAdTracking != nil && *ar.LimitAdTracking:
+		return false
+	case ar.UsPrivacy != nil && len(*ar.UsPrivacy) == 4 && (*ar.UsPrivacy)[2] == 'Y':
+		return false
+	case ar.GdprApplies != nil && *ar.GdprApplies && (ar.TcfConsent == nil || *ar.TcfConsent == ""):
+		return false
+	}
+	return true
+}
+
+// StripProfile clears the personal profile fields (age, birth date, gender, email and
+// phone number) and returns the payload keys that were set.
+func (ar *AuthenticationRequest) StripProfile() []string {
+	var stripped []string
+	if ar.Age != nil || ar.BirthDate != nil {
+		stripped = append(stripped, "age")
+	}
+	if ar.hasGender() {
+		stripped = append(stripped, "gender")
+	}
+	if ar.Email != nil {
+		stripped = append(stripped, "email")
+	}
+	if ar.PhoneNumber != nil {
+		stripped = append(stripped, "phoneNumber")
+	}
+	ar.Age, ar.BirthDate, ar.Gender, ar.Email, ar.PhoneNumber = nil, nil, nil, nil, nil
+	return stripped
+}
+
+// consentErrors returns the violations of the consent fields.
+func (ar *AuthenticationRequest) consentErrors() ValidationErrors {
+	var errs ValidationErrors
+	if ar.TcfConsent != nil && *ar.TcfConsent != "" && !tcfConsentRegex.MatchString(*ar.TcfConsent) {
+		errs = append(errs, &FieldError{Field: "TcfConsent", JSONKey: "tcfConsent", Rule: RuleFormat, Message: "tcfConsent must be a TCF v2 consent string"})
+	}
+	if ar.UsPrivacy != nil && *ar.UsPrivacy != "" && !usPrivacyRegex.MatchString(*ar.UsPrivacy) {
+		errs = append(errs, &FieldError{Field: "UsPrivacy", JSONKey: "usPrivacy", Rule: RuleFormat, Message: `usPrivacy must be a US privacy string such as "1YNN"`})
+	}
+	if ar.Region != nil && *ar.Region != "" && !regionRegex.MatchString(*ar.Region) {
+		errs = append(errs, &FieldError{Field: "Region", JSONKey: "region", Rule: RuleFormat, Message: `region must be an ISO 3166 code such as "DE" or "US-CA"`})
+	}
+	return errs
+}
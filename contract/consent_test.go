@@ -0,0 +1,89 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHasProfileConsent(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []AuthenticationRequestOptions
+		expected bool
+	}{
+		{name: "no consent fields", expected: true},
+		{name: "gdpr with consent", opts: []AuthenticationRequestOptions{WithGdprConsent("CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")}, expected: true},
+		{name: "gdpr without consent", opts: []AuthenticationRequestOptions{WithGdprConsent("")}, expected: false},
+		{name: "us privacy not opted out", opts: []AuthenticationRequestOptions{WithUsPrivacy("1YNN")}, expected: true},
+		{name: "us privacy opted out", opts: []AuthenticationRequestOptions{WithUsPrivacy("1YYN")}, expected: false},
+		{name: "limit ad tracking", opts: []AuthenticationRequestOptions{WithLimitAdTracking(true)}, expected: false},
+		{name: "tracking allowed", opts: []AuthenticationRequestOptions{WithLimitAdTracking(false)}, expected: true},
+		{name: "child directed", opts: []AuthenticationRequestOptions{WithChildDirected(true)}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewAuthenticationRequest("user123", tt.opts...).HasProfileConsent(); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDefaultConsentPolicy(t *testing.T) {
+	req := NewAuthenticationRequest("user123",
+		WithBirthDate(time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		WithGender(2),
+		WithEmail("kid@example.com"),
+		WithSub(1, "campaign"),
+		WithChildDirected(true),
+	)
+
+	stripped := DefaultConsentPolicy(req)
+	if expected := []string{"age", "gender", "email"}; !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("expected stripped %v, got %v", expected, stripped)
+	}
+	if req.BirthDate != nil || req.Gender != nil || req.Email != nil {
+		t.Errorf("expected profile to be stripped, got %+v", req)
+	}
+	if req.Sub1 == nil || *req.ChildDirected != true {
+		t.Error("expected tracking and consent fields to be kept")
+	}
+
+	req = NewAuthenticationRequest("user123", WithAge(30), WithUsPrivacy("1YNN"))
+	if stripped := DefaultConsentPolicy(req); stripped != nil || req.Age == nil {
+		t.Errorf("expected profile to be kept with consent, stripped %v", stripped)
+	}
+}
+
+func TestValidateAuthenticationRequest_Consent(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []AuthenticationRequestOptions
+		expectedKey string
+	}{
+		{name: "valid consent", opts: []AuthenticationRequestOptions{WithGdprConsent("CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA.YAAAAAAAAAAA"), WithUsPrivacy("1---"), WithRegion("US-CA")}},
+		{name: "tcf v1 string", opts: []AuthenticationRequestOptions{WithGdprConsent("BOEFEAyOEFEAyAHABDENAI4AAAB9vABAASA")}, expectedKey: "tcfConsent"},
+		{name: "tcf with spaces", opts: []AuthenticationRequestOptions{WithGdprConsent("C consent")}, expectedKey: "tcfConsent"},
+		{name: "us privacy wrong version", opts: []AuthenticationRequestOptions{WithUsPrivacy("2YNN")}, expectedKey: "usPrivacy"},
+		{name: "us privacy lowercase", opts: []AuthenticationRequestOptions{WithUsPrivacy("1ynn")}, expectedKey: "usPrivacy"},
+		{name: "region lowercase", opts: []AuthenticationRequestOptions{WithRegion("de")}, expectedKey: "region"},
+		{name: "region name", opts: []AuthenticationRequestOptions{WithRegion("Germany")}, expectedKey: "region"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAuthenticationRequest("user123", tt.opts...).ValidateAuthenticationRequest()
+			if tt.expectedKey == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if v, ok := AsValidationErrors(err); !ok || len(v.Field(tt.expectedKey)) != 1 {
+				t.Errorf("expected %s violation, got %v", tt.expectedKey, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+)
+
+// E.164 allows at most 15 digits, country code included.
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+)
+
+// NormalizePhoneNumber returns phone in E.164 form, such as "+14155550123".
+// Spaces, dashes, dots and parentheses are removed and a leading "00" is read as "+".
+// A number without a country code gets defaultCountryCode, such as "1" or "+44",
+// after dropping a single national trunk prefix "0". Without a default country code,
+// such a number is returned as national digits, with only the separators removed; it must
+// still have 7 to 15 digits.
+func NormalizePhoneNumber(phone, defaultCountryCode string) (string, error) {
+	normalized, err := normalizePhoneNumber(phone, defaultCountryCode)
+	if err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// NormalizePhoneNumber rewrites PhoneNumber in E.164 form, see NormalizePhoneNumber.
+// Errors are returned as ValidationErrors.
+func (ar *AuthenticationRequest) NormalizePhoneNumber(defaultCountryCode string) error {
+	if ar.PhoneNumber == nil || *ar.PhoneNumber == "" {
+		return nil
+	}
+	normalized, err := normalizePhoneNumber(*ar.PhoneNumber, defaultCountryCode)
+	if err != nil {
+		return ValidationErrors{err}
+	}
+	ar.PhoneNumber = &normalized
+	return nil
+}
+
+func normalizePhoneNumber(phone, defaultCountryCode string) (string, *FieldError) {
+	digits, international, err := parsePhoneNumber(phone)
+	if err != nil {
+		return "", err
+	}
+	if !international {
+		countryCode := strings.TrimPrefix(defaultCountryCode, "+")
+		if countryCode == "" {
+			if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+				return "", phoneError(fmt.Sprintf("phone number must have %d to %d digits", minPhoneDigits, maxPhoneDigits))
+			}
+			return digits, nil
+		}
+		if !isDigits(countryCode) || countryCode[0] == '0' || len(countryCode) > 3 {
+			return "", phoneError(fmt.Sprintf("invalid default country code %q", defaultCountryCode))
+		}
+		digits = countryCode + strings.TrimPrefix(digits, "0")
+	}
+	if digits[0] == '0' {
+		return "", phoneError("phone number country code cannot start with 0")
+	}
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		return "", phoneError(fmt.Sprintf("phone number must have %d to %d digits including the country code", minPhoneDigits, maxPhoneDigits))
+	}
+	return "+" + digits, nil
+}
+
+// parsePhoneNumber strips the separators from phone and reports whether it carries a country code.
+func parsePhoneNumber(phone string) (string, bool, *FieldError) {
+	phone = strings.TrimSpace(phone)
+	international := false
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		phone, international = phone[1:], true
+	case strings.HasPrefix(phone, "00"):
+		phone, international = phone[2:], true
+	}
+
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, phoneError("invalid phone number format")
+		}
+	}
+	if digits.Len() == 0 || digits.Len() > maxPhoneDigits {
+		return "", false, phoneError("invalid phone number format")
+	}
+	return digits.String(), international, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func phoneError(message string) *FieldError {
+	return &FieldError{Field: "PhoneNumber", JSONKey: "phoneNumber", Rule: RuleFormat, Message: message}
+}
@@ -0,0 +1,73 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name               string
+		phone              string
+		defaultCountryCode string
+		expected           string
+		errMsg             string
+	}{
+		{name: "already E.164", phone: "+14155550123", expected: "+14155550123"},
+		{name: "international with separators", phone: "+1 (415) 555-0123", expected: "+14155550123"},
+		{name: "international with 00 prefix", phone: "0044 20 7946 0958", expected: "+442079460958"},
+		{name: "national with default country code", phone: "415.555.0123", defaultCountryCode: "1", expected: "+14155550123"},
+		{name: "national with trunk prefix", phone: "020 7946 0958", defaultCountryCode: "+44", expected: "+442079460958"},
+		{name: "national without default country code", phone: "(415) 555-0123", expected: "4155550123"},
+		{name: "invalid default country code", phone: "415-555-0123", defaultCountryCode: "abc", errMsg: `invalid default country code "abc"`},
+		{name: "letters", phone: "+1 415 CALL NOW", errMsg: "invalid phone number format"},
+		{name: "too short", phone: "+12345", errMsg: "phone number must have 7 to 15 digits including the country code"},
+		{name: "too long", phone: "+1234567890123456", errMsg: "invalid phone number format"},
+		{name: "country code starting with 0", phone: "+0123456789", errMsg: "phone number country code cannot start with 0"},
+		{name: "national single digit", phone: "1", errMsg: "phone number must have 7 to 15 digits"},
+		{name: "national zero", phone: "0", errMsg: "phone number must have 7 to 15 digits"},
+		{name: "national short with separator", phone: "12-3", errMsg: "phone number must have 7 to 15 digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhoneNumber(tt.phone, tt.defaultCountryCode)
+			if tt.errMsg != "" {
+				if err == nil || err.Error() != tt.errMsg {
+					t.Errorf("expected error '%s', got %v", tt.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAuthenticationRequest_NormalizePhoneNumber(t *testing.T) {
+	req := NewAuthenticationRequest("user123", WithPhoneNumber("(415) 555-0123"))
+	if err := req.NormalizePhoneNumber("1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *req.PhoneNumber != "+14155550123" {
+		t.Errorf("expected +14155550123, got %s", *req.PhoneNumber)
+	}
+
+	req = NewAuthenticationRequest("user123", WithPhoneNumber("555-0123"))
+	err := req.NormalizePhoneNumber("abc")
+	var fieldErr *FieldError
+	if _, ok := AsValidationErrors(err); !ok || !errors.As(err, &fieldErr) || fieldErr.JSONKey != "phoneNumber" {
+		t.Errorf("expected phoneNumber ValidationErrors, got %v", err)
+	}
+	if *req.PhoneNumber != "555-0123" {
+		t.Errorf("expected phone number to be left unchanged on error, got %s", *req.PhoneNumber)
+	}
+
+	if err := NewAuthenticationRequest("user123").NormalizePhoneNumber(""); err != nil {
+		t.Errorf("expected no error without phone number, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+// PersonalDataKeys lists the payload keys that PrivacyMode applies to.
+var PersonalDataKeys = []string{"email", "phoneNumber"}
+
+// ProfileDataKeys lists the keys of the user profile other than PersonalDataKeys, which
+// StripProfile clears along with them.
+var ProfileDataKeys = []string{"age", "gender", jsonKeyBirthDate}
+
+// ConsentDataKeys lists the payload keys of the consent fields.
+var ConsentDataKeys = []string{"gdprApplies", "tcfConsent", "usPrivacy", "limitAdTracking", "childDirected", "region"}
+
+// SensitiveDataKeys returns PersonalDataKeys, ProfileDataKeys and ConsentDataKeys, every key
+// describing the user or their consent.
+func SensitiveDataKeys() []string {
+	keys := append([]string(nil), PersonalDataKeys...)
+	keys = append(keys, ProfileDataKeys...)
+	return append(keys, ConsentDataKeys...)
+}
+
+// ApplyPrivacyMode rewrites the personal data in a payload built by GetParsedAuthenticationRequestData.
+// With enum.PrivacyModeHash each value is replaced by HashPersonalData of it. With enum.PrivacyModeRaw
+// or an empty mode it is kept, and with any other mode it is removed, so a misconfigured mode never
+// sends raw values. It returns the keys that were hashed and omitted.
+func ApplyPrivacyMode(data map[string]interface{}, mode enum.PrivacyMode) (hashed, omitted []string) {
+	for _, key := range PersonalDataKeys {
+		value, ok := data[key].(string)
+		if !ok {
+			continue
+		}
+		switch mode {
+		case "", enum.PrivacyModeRaw:
+		case enum.PrivacyModeHash:
+			data[key] = HashPersonalData(value)
+			hashed = append(hashed, key)
+		default:
+			delete(data, key)
+			omitted = append(omitted, key)
+		}
+	}
+	return hashed, omitted
+}
+
+// HashPersonalData returns the hex SHA-256 of value after trimming and lowercasing it.
+// Phone numbers should be normalized to E.164 first, as the SDK does before hashing.
+func HashPersonalData(value string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+)
+
+func TestApplyPrivacyMode(t *testing.T) {
+	emailHash := HashPersonalData("alice@example.com")
+	phoneHash := HashPersonalData("+14155550123")
+
+	tests := []struct {
+		name            string
+		mode            enum.PrivacyMode
+		expected        map[string]interface{}
+		expectedHashed  []string
+		expectedOmitted []string
+	}{
+		{
+			name: "raw",
+			mode: enum.PrivacyModeRaw,
+			expected: map[string]interface{}{
+				"publisherUserId": "user123",
+				"email":           " Alice@Example.com",
+				"phoneNumber":     "+14155550123",
+			},
+		},
+		{
+			name: "hash",
+			mode: enum.PrivacyModeHash,
+			expected: map[string]interface{}{
+				"publisherUserId": "user123",
+				"email":           emailHash,
+				"phoneNumber":     phoneHash,
+			},
+			expectedHashed: []string{"email", "phoneNumber"},
+		},
+		{
+			name:            "omit",
+			mode:            enum.PrivacyModeOmit,
+			expected:        map[string]interface{}{"publisherUserId": "user123"},
+			expectedOmitted: []string{"email", "phoneNumber"},
+		},
+		{
+			name:            "unknown mode omits",
+			mode:            "Hash",
+			expected:        map[string]interface{}{"publisherUserId": "user123"},
+			expectedOmitted: []string{"email", "phoneNumber"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewAuthenticationRequest("user123", WithEmail(" Alice@Example.com"), WithPhoneNumber("+14155550123")).GetParsedAuthenticationRequestData()
+			hashed, omitted := ApplyPrivacyMode(data, tt.mode)
+
+			if !reflect.DeepEqual(data, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, data)
+			}
+			if !reflect.DeepEqual(hashed, tt.expectedHashed) || !reflect.DeepEqual(omitted, tt.expectedOmitted) {
+				t.Errorf("expected hashed %v and omitted %v, got %v and %v", tt.expectedHashed, tt.expectedOmitted, hashed, omitted)
+			}
+		})
+	}
+}
+
+func TestHashPersonalData(t *testing.T) {
+	// SHA-256 of "alice@example.com".
+	expected := "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976"
+	if got := HashPersonalData("  Alice@Example.COM "); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestValidateAuthenticationRequest_PrivacyMode(t *testing.T) {
+	if err := NewAuthenticationRequest("user123", WithPrivacyMode(enum.PrivacyModeHash)).ValidateAuthenticationRequest(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := NewAuthenticationRequest("user123", WithPrivacyMode("encrypt")).ValidateAuthenticationRequest()
+	if v, ok := AsValidationErrors(err); !ok || len(v.Field("privacyMode")) != 1 {
+		t.Errorf("expected privacyMode violation, got %v", err)
+	}
+}
+
+func TestSensitiveDataKeys(t *testing.T) {
+	var ar AuthenticationRequest
+	for _, key := range SensitiveDataKeys() {
+		if !ar.isKnownKey(key) {
+			t.Errorf("expected %q to be a key of AuthenticationRequest", key)
+		}
+	}
+	if keys := SensitiveDataKeys(); len(keys) != len(PersonalDataKeys)+len(ProfileDataKeys)+len(ConsentDataKeys) {
+		t.Errorf("expected every personal, profile and consent key, got %v", keys)
+	}
+}
@@ -0,0 +1,86 @@
+package contract
+
+import (
+	"errors"
+	"strings"
+)
+
+// Validation rules reported in FieldError.Rule.
+const (
+	RuleRequired       = "required"
+	RuleMin            = "min"
+	RuleMax            = "max"
+	RuleMinimumAge     = "minimum_age"
+	RuleOneOf          = "one_of"
+	RuleFormat         = "format"
+	RuleRange          = "range"
+	RuleMaxLength      = "max_length"
+	RuleNoControlChars = "no_control_chars"
+	RuleReservedKey    = "reserved_key"
+)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	// Field is the Go field name, such as "PhoneNumber".
+	Field string `json:"field"`
+	// JSONKey is the key of the field in the API payload, such as "phoneNumber".
+	JSONKey string `json:"key"`
+	// Rule is the violated rule, one of the Rule constants.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error returns the message of the violation.
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors holds every violation found in a request. It unwraps to its
+// FieldErrors, so errors.As can extract either the whole list or a single *FieldError.
+type ValidationErrors []*FieldError
+
+// Error returns the messages of all violations separated by "; ".
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual violations.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Field returns the violations reported for the given JSON key.
+func (v ValidationErrors) Field(jsonKey string) []*FieldError {
+	var found []*FieldError
+	for _, e := range v {
+		if e.JSONKey == jsonKey {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// err returns v as an error, or nil when there is no violation.
+func (v ValidationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// AsValidationErrors extracts the ValidationErrors wrapped in err, if any.
+func AsValidationErrors(err error) (ValidationErrors, bool) {
+	var v ValidationErrors
+	if errors.As(err, &v) {
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,122 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateAuthenticationRequest_AllViolations(t *testing.T) {
+	req := NewAuthenticationRequest("",
+		WithAge(-1),
+		WithGender(3),
+		WithEmail("invalid"),
+		WithSub(1, strings.Repeat("a", MaxFieldLength+1)),
+		WithMediaCampaignName("spring\nsale"),
+	)
+
+	err := req.ValidateAuthenticationRequest()
+	v, ok := AsValidationErrors(err)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %T", err)
+	}
+
+	expected := []struct {
+		key  string
+		rule string
+	}{
+		{"publisherUserId", RuleRequired},
+		{"age", RuleMin},
+		{"gender", RuleOneOf},
+		{"email", RuleFormat},
+		{"sub1", RuleMaxLength},
+		{"mediaCampaignName", RuleNoControlChars},
+	}
+	if len(v) != len(expected) {
+		t.Fatalf("expected %d violations, got %d: %v", len(expected), len(v), v)
+	}
+	for i, e := range expected {
+		if v[i].JSONKey != e.key || v[i].Rule != e.rule {
+			t.Errorf("expected violation %d to be %s/%s, got %s/%s", i, e.key, e.rule, v[i].JSONKey, v[i].Rule)
+		}
+	}
+}
+
+func TestValidateAuthenticationRequest_FieldRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *AuthenticationRequest
+		expectedKey  string
+		expectedRule string
+	}{
+		{
+			name:         "sub at max length",
+			request:      NewAuthenticationRequest("user123", WithSub(5, strings.Repeat("é", MaxFieldLength))),
+			expectedRule: "",
+		},
+		{
+			name:         "media source too long",
+			request:      NewAuthenticationRequest("user123", WithMediaSource("google", strings.Repeat("1", MaxFieldLength+1))),
+			expectedKey:  "mediaSourceId",
+			expectedRule: RuleMaxLength,
+		},
+		{
+			name:         "user group with tab",
+			request:      NewAuthenticationRequest("user123", WithUserGroup("vip\tgroup")),
+			expectedKey:  "userGroup",
+			expectedRule: RuleNoControlChars,
+		},
+		{
+			name:         "publisher user ID with null byte",
+			request:      NewAuthenticationRequest("user\x00123"),
+			expectedKey:  "publisherUserId",
+			expectedRule: RuleNoControlChars,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.ValidateAuthenticationRequest()
+			if tt.expectedRule == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("expected FieldError, got %v", err)
+			}
+			if fieldErr.JSONKey != tt.expectedKey || fieldErr.Rule != tt.expectedRule {
+				t.Errorf("expected %s/%s, got %s/%s", tt.expectedKey, tt.expectedRule, fieldErr.JSONKey, fieldErr.Rule)
+			}
+		})
+	}
+}
+
+func TestValidationErrors_ErrorsJoin(t *testing.T) {
+	err := fmt.Errorf("authenticate: %w", errors.Join(errors.New("other"), NewAuthenticationRequest("user123", WithEmail("x")).ValidateAuthenticationRequest()))
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Email" {
+		t.Errorf("expected Email FieldError through errors.Join, got %v", err)
+	}
+	v, ok := AsValidationErrors(err)
+	if !ok || len(v.Field("email")) != 1 {
+		t.Errorf("expected ValidationErrors through errors.Join, got %v", err)
+	}
+}
+
+func TestValidationErrors_Error(t *testing.T) {
+	v := ValidationErrors{
+		{JSONKey: "age", Message: "age must be a non-negative integer"},
+		{JSONKey: "email", Message: "invalid email format"},
+	}
+	if expected := "age must be a non-negative integer; invalid email format"; v.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, v.Error())
+	}
+	if v.err() == nil || ValidationErrors(nil).err() != nil {
+		t.Error("expected err to return nil only for an empty list")
+	}
+}
@@ -0,0 +1,10 @@
+package enum
+
+// CircuitState is the state of the client-side circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
@@ -0,0 +1,35 @@
+package enum
+
+import "testing"
+
+func TestCircuitStateConstants(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    CircuitState
+		expected string
+	}{
+		{
+			name:     "CircuitClosed constant",
+			state:    CircuitClosed,
+			expected: "closed",
+		},
+		{
+			name:     "CircuitOpen constant",
+			state:    CircuitOpen,
+			expected: "open",
+		},
+		{
+			name:     "CircuitHalfOpen constant",
+			state:    CircuitHalfOpen,
+			expected: "half-open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if string(tt.state) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, string(tt.state))
+			}
+		})
+	}
+}
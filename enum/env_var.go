@@ -5,4 +5,8 @@ type EnvVar string
 const (
 	TYRADS_API_KEY    EnvVar = "TYRADS_API_KEY"
 	TYRADS_API_SECRET EnvVar = "TYRADS_API_SECRET"
+	// The variables below are read by the tyrads command.
+	TYRADS_LANGUAGE        EnvVar = "TYRADS_LANGUAGE"
+	TYRADS_API_BASE_URL    EnvVar = "TYRADS_API_BASE_URL"
+	TYRADS_IFRAME_BASE_URL EnvVar = "TYRADS_IFRAME_BASE_URL"
 )
@@ -18,6 +18,21 @@ func TestEnvVarConstants(t *testing.T) {
 			envVar:   TYRADS_API_SECRET,
 			expected: "TYRADS_API_SECRET",
 		},
+		{
+			name:     "TYRADS_LANGUAGE constant",
+			envVar:   TYRADS_LANGUAGE,
+			expected: "TYRADS_LANGUAGE",
+		},
+		{
+			name:     "TYRADS_API_BASE_URL constant",
+			envVar:   TYRADS_API_BASE_URL,
+			expected: "TYRADS_API_BASE_URL",
+		},
+		{
+			name:     "TYRADS_IFRAME_BASE_URL constant",
+			envVar:   TYRADS_IFRAME_BASE_URL,
+			expected: "TYRADS_IFRAME_BASE_URL",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,89 @@
+package enum
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Gender is the user gender sent in the authentication request.
+// The API accepts GenderMale and GenderFemale only; GenderUnspecified means the field is not sent.
+type Gender int
+
+const (
+	GenderUnspecified Gender = 0
+	GenderMale        Gender = 1
+	GenderFemale      Gender = 2
+)
+
+// ParseGender parses a gender from its common string forms, case-insensitively:
+// "male", "m" or "1", "female", "f" or "2". An empty string, "unspecified", "unknown",
+// "other" and "o" parse to GenderUnspecified, since the API has no value for them.
+func ParseGender(s string) (Gender, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "male", "m", "1":
+		return GenderMale, nil
+	case "female", "f", "2":
+		return GenderFemale, nil
+	case "", "unspecified", "unknown", "u", "other", "o", "0":
+		return GenderUnspecified, nil
+	default:
+		return GenderUnspecified, fmt.Errorf("invalid gender %q", s)
+	}
+}
+
+// IsValid reports whether g can be sent to the API.
+func (g Gender) IsValid() bool {
+	return g == GenderMale || g == GenderFemale
+}
+
+func (g Gender) String() string {
+	switch g {
+	case GenderUnspecified:
+		return "unspecified"
+	case GenderMale:
+		return "male"
+	case GenderFemale:
+		return "female"
+	default:
+		return "Gender(" + strconv.Itoa(int(g)) + ")"
+	}
+}
+
+// MarshalText encodes g as "male", "female" or "unspecified".
+func (g Gender) MarshalText() ([]byte, error) {
+	if g != GenderUnspecified && !g.IsValid() {
+		return nil, fmt.Errorf("invalid gender %d", int(g))
+	}
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText decodes any form accepted by ParseGender.
+func (g *Gender) UnmarshalText(text []byte) error {
+	parsed, err := ParseGender(string(text))
+	if err != nil {
+		return err
+	}
+	*g = parsed
+	return nil
+}
+
+// MarshalJSON encodes g as the number expected by the API.
+func (g Gender) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(g))
+}
+
+// UnmarshalJSON decodes either the API number or any string form accepted by ParseGender.
+func (g *Gender) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*g = Gender(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid gender %s", data)
+	}
+	return g.UnmarshalText([]byte(s))
+}
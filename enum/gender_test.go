@@ -0,0 +1,108 @@
+package enum
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseGender(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Gender
+		wantErr  bool
+	}{
+		{input: "male", expected: GenderMale},
+		{input: "M", expected: GenderMale},
+		{input: "1", expected: GenderMale},
+		{input: " Female ", expected: GenderFemale},
+		{input: "f", expected: GenderFemale},
+		{input: "2", expected: GenderFemale},
+		{input: "", expected: GenderUnspecified},
+		{input: "other", expected: GenderUnspecified},
+		{input: "unknown", expected: GenderUnspecified},
+		{input: "3", wantErr: true},
+		{input: "mal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseGender(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGender_String(t *testing.T) {
+	tests := []struct {
+		gender   Gender
+		expected string
+	}{
+		{GenderUnspecified, "unspecified"},
+		{GenderMale, "male"},
+		{GenderFemale, "female"},
+		{Gender(7), "Gender(7)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.gender.String(); got != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestGender_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Gender Gender `json:"gender"`
+	}{GenderFemale})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"gender":2}` {
+		t.Errorf("expected numeric gender, got %s", data)
+	}
+
+	tests := []struct {
+		input    string
+		expected Gender
+		wantErr  bool
+	}{
+		{input: `1`, expected: GenderMale},
+		{input: `"f"`, expected: GenderFemale},
+		{input: `"male"`, expected: GenderMale},
+		{input: `"x-y"`, wantErr: true},
+		{input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var g Gender
+			err := json.Unmarshal([]byte(tt.input), &g)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if g != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, g)
+			}
+		})
+	}
+}
+
+func TestGender_Text(t *testing.T) {
+	text, err := GenderMale.MarshalText()
+	if err != nil || string(text) != "male" {
+		t.Errorf("expected 'male', got '%s', %v", text, err)
+	}
+	if _, err := Gender(9).MarshalText(); err == nil {
+		t.Error("expected error for unknown gender")
+	}
+
+	var g Gender
+	if err := g.UnmarshalText([]byte("F")); err != nil || g != GenderFemale {
+		t.Errorf("expected female, got %v, %v", g, err)
+	}
+}
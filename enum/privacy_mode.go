@@ -0,0 +1,18 @@
+package enum
+
+// PrivacyMode controls how personal data (email and phone number) is sent to the API.
+type PrivacyMode string
+
+const (
+	// PrivacyModeRaw sends personal data as given. It is the default.
+	PrivacyModeRaw PrivacyMode = "raw"
+	// PrivacyModeHash sends the hex SHA-256 of the normalized, lowercased value.
+	PrivacyModeHash PrivacyMode = "hash"
+	// PrivacyModeOmit does not send personal data.
+	PrivacyModeOmit PrivacyMode = "omit"
+)
+
+// IsValid reports whether m is one of the PrivacyMode constants.
+func (m PrivacyMode) IsValid() bool {
+	return m == PrivacyModeRaw || m == PrivacyModeHash || m == PrivacyModeOmit
+}
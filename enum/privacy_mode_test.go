@@ -0,0 +1,25 @@
+package enum
+
+import "testing"
+
+func TestPrivacyMode_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     PrivacyMode
+		expected bool
+	}{
+		{name: "raw", mode: PrivacyModeRaw, expected: true},
+		{name: "hash", mode: PrivacyModeHash, expected: true},
+		{name: "omit", mode: PrivacyModeOmit, expected: true},
+		{name: "empty", mode: "", expected: false},
+		{name: "unknown", mode: "encrypt", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mode.IsValid(); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrWildcardCredentials is returned by CORSOptions.Validate when credentials are allowed
+// together with the "*" origin.
+var ErrWildcardCredentials = errors.New(`cors: AllowCredentials requires explicit origins, not "*"`)
+
+// CORSOptions configures cross-origin access to a handler.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to call the handler. "*" allows any origin,
+	// but only without AllowCredentials.
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers allowed in preflight requests.
+	AllowedHeaders []string
+	// AllowCredentials allows cookies and authorization headers to be sent cross-origin,
+	// from the explicitly listed origins only. "*" is then ignored, so any website cannot
+	// read the responses of a signed-in user.
+	AllowCredentials bool
+	// MaxAge controls how long browsers may cache preflight results.
+	MaxAge time.Duration
+}
+
+// handle writes the CORS headers for r and reports whether r was a preflight
+// request that has been fully answered.
+func (c *CORSOptions) handle(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	w.Header().Add("Vary", "Origin")
+	if origin != "" && c.originAllowed(origin) {
+		if slices.Contains(c.AllowedOrigins, "*") && !c.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			if len(c.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+			}
+			if c.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+			}
+		}
+	}
+
+	if preflight {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return preflight
+}
+
+// Validate returns ErrWildcardCredentials if AllowCredentials is set with the "*" origin.
+func (c *CORSOptions) Validate() error {
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		return ErrWildcardCredentials
+	}
+	return nil
+}
+
+func (c *CORSOptions) originAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if (allowed == "*" && !c.AllowCredentials) || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSOptions_Handle(t *testing.T) {
+	tests := []struct {
+		name                string
+		cors                CORSOptions
+		method              string
+		origin              string
+		requestMethod       string
+		expectedPreflight   bool
+		expectedAllowOrigin string
+		expectedCredentials string
+		expectedMaxAge      string
+	}{
+		{
+			name:                "allowed origin",
+			cors:                CORSOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method:              http.MethodGet,
+			origin:              "https://app.example.com",
+			expectedAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:   "disallowed origin",
+			cors:   CORSOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodGet,
+			origin: "https://evil.example.com",
+		},
+		{
+			name:                "wildcard without credentials",
+			cors:                CORSOptions{AllowedOrigins: []string{"*"}},
+			method:              http.MethodGet,
+			origin:              "https://app.example.com",
+			expectedAllowOrigin: "*",
+		},
+		{
+			name:   "wildcard with credentials rejected",
+			cors:   CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			method: http.MethodGet,
+			origin: "https://evil.example.com",
+		},
+		{
+			name:                "explicit origin with credentials",
+			cors:                CORSOptions{AllowedOrigins: []string{"*", "https://app.example.com"}, AllowCredentials: true},
+			method:              http.MethodGet,
+			origin:              "https://app.example.com",
+			expectedAllowOrigin: "https://app.example.com",
+			expectedCredentials: "true",
+		},
+		{
+			name:                "preflight",
+			cors:                CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 10 * time.Minute},
+			method:              http.MethodOptions,
+			origin:              "https://app.example.com",
+			requestMethod:       http.MethodGet,
+			expectedPreflight:   true,
+			expectedAllowOrigin: "https://app.example.com",
+			expectedMaxAge:      "600",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/iframe", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			rec := httptest.NewRecorder()
+
+			preflight := tt.cors.handle(rec, req)
+
+			if preflight != tt.expectedPreflight {
+				t.Errorf("expected preflight %v, got %v", tt.expectedPreflight, preflight)
+			}
+			if preflight && rec.Code != http.StatusNoContent {
+				t.Errorf("expected status 204 for preflight, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedAllowOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin '%s', got '%s'", tt.expectedAllowOrigin, got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.expectedCredentials {
+				t.Errorf("expected Access-Control-Allow-Credentials '%s', got '%s'", tt.expectedCredentials, got)
+			}
+			if got := rec.Header().Get("Access-Control-Max-Age"); got != tt.expectedMaxAge {
+				t.Errorf("expected Access-Control-Max-Age '%s', got '%s'", tt.expectedMaxAge, got)
+			}
+		})
+	}
+}
+
+func TestCORSOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cors        CORSOptions
+		expectedErr error
+	}{
+		{name: "explicit origins with credentials", cors: CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}},
+		{name: "wildcard without credentials", cors: CORSOptions{AllowedOrigins: []string{"*"}}},
+		{name: "wildcard with credentials", cors: CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}, expectedErr: ErrWildcardCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cors.Validate(); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
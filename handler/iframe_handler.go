@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+// Query parameters accepted by IframeHandler.
+const (
+	DeeplinkParam = "to"
+	WidgetParam   = "widget"
+)
+
+// Error codes reported in ErrorResponse.
+const (
+	ErrCodeInvalidParameter        = "invalid_parameter"
+	ErrCodeMethodNotAllowed        = "method_not_allowed"
+	ErrCodeUnauthenticated         = "unauthenticated"
+	ErrCodeInvalidUser             = "invalid_user"
+	ErrCodeRateLimited             = "rate_limited"
+	ErrCodeUpstreamError           = "upstream_error"
+	ErrCodeUpstreamUnavailable     = "upstream_unavailable"
+	ErrCodeUpstreamTimeout         = "upstream_timeout"
+	ErrCodeUpstreamInvalidResponse = "upstream_invalid_response"
+	ErrCodeInternal                = "internal_error"
+)
+
+// ErrUnauthenticated can be returned by a UserResolver when the incoming request
+// has no signed-in user. The handler answers with 401.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+var paramValueRegex = regexp.MustCompile(`^[A-Za-z0-9_./-]{1,128}$`)
+
+// UserResolver builds the AuthenticationRequest for the user behind an incoming request,
+// typically from a session cookie or bearer token.
+type UserResolver func(r *http.Request) (*tyrads.AuthenticationRequest, error)
+
+// IframeUrlResponse is the JSON body returned on success.
+type IframeUrlResponse struct {
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ErrorResponse is the JSON body returned on failure.
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes why a request failed.
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields lists the invalid fields when Code is invalid_user.
+	Fields []*contract.FieldError `json:"fields,omitempty"`
+}
+
+// IframeHandler is an http.Handler that authenticates the current user and returns
+// an iframe or premium widget URL as JSON, for SPA frontends.
+type IframeHandler struct {
+	sdk         *tyrads.TyrAdsSdk
+	resolveUser UserResolver
+
+	// TokenTTL is added to the current time to populate expiresAt.
+	// When zero, expiresAt is omitted from the response.
+	TokenTTL time.Duration
+	// AllowedDeeplinks restricts the values accepted for the "to" parameter. Empty allows any.
+	AllowedDeeplinks []string
+	// AllowedWidgets restricts the values accepted for the "widget" parameter. Empty allows any.
+	AllowedWidgets []string
+	// CORS enables cross-origin requests when set.
+	CORS *CORSOptions
+	// OnError is called with every error before the JSON error response is written.
+	OnError func(r *http.Request, err error)
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+type IframeHandlerOptions func(*IframeHandler)
+
+// NewIframeHandler creates a new IframeHandler.
+//
+// Parameters:
+//   - sdk: The SDK used to authenticate users and build URLs.
+//   - resolveUser: Callback returning the AuthenticationRequest for the current user.
+//   - opts: Optional functions adjusting the handler fields.
+//
+// Returns:
+//   - *IframeHandler: The configured handler.
+func NewIframeHandler(sdk *tyrads.TyrAdsSdk, resolveUser UserResolver, opts ...IframeHandlerOptions) *IframeHandler {
+	h := &IframeHandler{
+		sdk:         sdk,
+		resolveUser: resolveUser,
+		Now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP handles GET requests with an optional "to" deeplink or "widget" name,
+// and answers CORS preflight requests when CORS is configured.
+func (h *IframeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.CORS != nil && h.CORS.handle(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET, OPTIONS")
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	deeplinkTo, widget, err := h.parseParams(r)
+	if err != nil {
+		h.reportError(r, err)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+
+	request, err := h.resolveUser(r)
+	if err == nil && request == nil {
+		err = ErrUnauthenticated
+	}
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	sign, err := h.sdk.AuthenticateWithContext(r.Context(), *request)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	var iframeUrl string
+	if widget != nil {
+		iframeUrl, err = h.sdk.IframePremiumWidget(sign, widget)
+	} else {
+		iframeUrl, err = h.sdk.IframeUrl(sign, deeplinkTo)
+	}
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	resp := IframeUrlResponse{URL: iframeUrl}
+	if h.TokenTTL > 0 {
+		expiresAt := h.Now().Add(h.TokenTTL).UTC()
+		resp.ExpiresAt = &expiresAt
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *IframeHandler) parseParams(r *http.Request) (deeplinkTo, widget *string, err error) {
+	query := r.URL.Query()
+	if query.Has(DeeplinkParam) && query.Has(WidgetParam) {
+		return nil, nil, fmt.Errorf("only one of %q and %q may be set", DeeplinkParam, WidgetParam)
+	}
+	if query.Has(DeeplinkParam) {
+		v := query.Get(DeeplinkParam)
+		if err := validateParam(DeeplinkParam, v, h.AllowedDeeplinks); err != nil {
+			return nil, nil, err
+		}
+		deeplinkTo = &v
+	}
+	if query.Has(WidgetParam) {
+		v := query.Get(WidgetParam)
+		if err := validateParam(WidgetParam, v, h.AllowedWidgets); err != nil {
+			return nil, nil, err
+		}
+		widget = &v
+	}
+	return deeplinkTo, widget, nil
+}
+
+func validateParam(name, value string, allowed []string) error {
+	if !paramValueRegex.MatchString(value) {
+		return fmt.Errorf("%s must be 1-128 characters of letters, digits, '_', '-', '.' or '/'", name)
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, value) {
+		return fmt.Errorf("%s %q is not allowed", name, value)
+	}
+	return nil
+}
+
+func (h *IframeHandler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	h.reportError(r, err)
+	status, code, message := classifyError(err)
+	detail := ErrorDetail{Code: code, Message: message}
+	if code == ErrCodeInvalidUser {
+		detail.Fields, _ = contract.AsValidationErrors(err)
+	}
+	writeJSON(w, status, ErrorResponse{Error: detail})
+}
+
+func (h *IframeHandler) reportError(r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+}
+
+// classifyError maps SDK errors to an HTTP status, an error code and a message that is safe to expose.
+func classifyError(err error) (int, string, string) {
+	var httpErr *client.HttpError
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized, ErrCodeUnauthenticated, "user is not authenticated"
+	case errors.Is(err, tyrads.ErrValidation):
+		return http.StatusUnprocessableEntity, ErrCodeInvalidUser, err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrCodeUpstreamTimeout, "offerwall service timed out"
+	case errors.Is(err, client.ErrRateLimited),
+		errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests:
+		return http.StatusTooManyRequests, ErrCodeRateLimited, "too many requests, try again later"
+	case errors.Is(err, client.ErrCircuitOpen):
+		return http.StatusServiceUnavailable, ErrCodeUpstreamUnavailable, "offerwall service is temporarily unavailable"
+	case errors.Is(err, tyrads.ErrRequest):
+		return http.StatusBadGateway, ErrCodeUpstreamError, "offerwall service is unavailable"
+	case errors.Is(err, tyrads.ErrInvalidResponse):
+		return http.StatusBadGateway, ErrCodeUpstreamInvalidResponse, "offerwall service returned an invalid response"
+	default:
+		return http.StatusInternalServerError, ErrCodeInternal, "internal error"
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, ErrorResponse{Error: ErrorDetail{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
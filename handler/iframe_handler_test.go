@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+func newTestSdk(t *testing.T, status int, body string) *tyrads.TyrAdsSdk {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return tyrads.NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+	})
+}
+
+func staticUser(id string) UserResolver {
+	return func(r *http.Request) (*tyrads.AuthenticationRequest, error) {
+		return contract.NewAuthenticationRequest(id), nil
+	}
+}
+
+func TestIframeHandler_Success(t *testing.T) {
+	sdk := newTestSdk(t, http.StatusOK, `{"data":{"token":"tok-123"}}`)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		target      string
+		expectedURL string
+	}{
+		{
+			name:        "plain",
+			target:      "/iframe",
+			expectedURL: "https://sdk.tyrads.com?token=tok-123",
+		},
+		{
+			name:        "with deeplink",
+			target:      "/iframe?to=offers",
+			expectedURL: "https://sdk.tyrads.com?token=tok-123&to=offers",
+		},
+		{
+			name:        "with widget",
+			target:      "/iframe?widget=premium-offers",
+			expectedURL: "https://sdk.tyrads.com/widget?token=tok-123&name=premium-offers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewIframeHandler(sdk, staticUser("user123"), func(h *IframeHandler) {
+				h.TokenTTL = time.Hour
+				h.Now = func() time.Time { return now }
+			})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var resp IframeUrlResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.URL != tt.expectedURL {
+				t.Errorf("expected URL '%s', got '%s'", tt.expectedURL, resp.URL)
+			}
+			if resp.ExpiresAt == nil || !resp.ExpiresAt.Equal(now.Add(time.Hour)) {
+				t.Errorf("expected expiresAt %v, got %v", now.Add(time.Hour), resp.ExpiresAt)
+			}
+			if rec.Header().Get("Cache-Control") != "no-store" {
+				t.Error("expected Cache-Control no-store")
+			}
+		})
+	}
+}
+
+func TestIframeHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		status       int
+		body         string
+		resolver     UserResolver
+		opts         []IframeHandlerOptions
+		sdk          *tyrads.TyrAdsSdk
+		prime        bool
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			target:       "/iframe",
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedErr:  ErrCodeMethodNotAllowed,
+		},
+		{
+			name:         "both parameters",
+			target:       "/iframe?to=offers&widget=x",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ErrCodeInvalidParameter,
+		},
+		{
+			name:         "empty deeplink",
+			target:       "/iframe?to=",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ErrCodeInvalidParameter,
+		},
+		{
+			name:         "invalid characters",
+			target:       "/iframe?widget=%3Cscript%3E",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ErrCodeInvalidParameter,
+		},
+		{
+			name:   "deeplink not in allowlist",
+			target: "/iframe?to=settings",
+			opts: []IframeHandlerOptions{
+				func(h *IframeHandler) { h.AllowedDeeplinks = []string{"offers"} },
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ErrCodeInvalidParameter,
+		},
+		{
+			name:   "unauthenticated",
+			target: "/iframe",
+			resolver: func(r *http.Request) (*tyrads.AuthenticationRequest, error) {
+				return nil, ErrUnauthenticated
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedErr:  ErrCodeUnauthenticated,
+		},
+		{
+			name:         "validation error",
+			target:       "/iframe",
+			resolver:     staticUser(""),
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedErr:  ErrCodeInvalidUser,
+		},
+		{
+			name:         "upstream rate limited",
+			target:       "/iframe",
+			status:       http.StatusTooManyRequests,
+			body:         `{"message":"Too many requests"}`,
+			expectedCode: http.StatusTooManyRequests,
+			expectedErr:  ErrCodeRateLimited,
+		},
+		{
+			name:   "client rate limited",
+			target: "/iframe",
+			sdk: tyrads.NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+				c.RateLimit = &config.RateLimitConfig{
+					PerEndpoint: map[string]config.RateLimit{"/auth": {RequestsPerSecond: 1}},
+					FailFast:    true,
+				}
+				c.SdkApiBaseURL = "http://127.0.0.1:0"
+			}),
+			prime:        true,
+			expectedCode: http.StatusTooManyRequests,
+			expectedErr:  ErrCodeRateLimited,
+		},
+		{
+			name:   "circuit open",
+			target: "/iframe",
+			sdk: tyrads.NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+				c.CircuitBreaker = &config.CircuitBreakerConfig{MinRequests: 1}
+				c.SdkApiBaseURL = "http://127.0.0.1:0"
+			}),
+			prime:        true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErr:  ErrCodeUpstreamUnavailable,
+		},
+		{
+			name:         "upstream error",
+			target:       "/iframe",
+			status:       http.StatusUnauthorized,
+			body:         `{"message":"Invalid API key"}`,
+			expectedCode: http.StatusBadGateway,
+			expectedErr:  ErrCodeUpstreamError,
+		},
+		{
+			name:         "upstream invalid response",
+			target:       "/iframe",
+			body:         `{"data":{}}`,
+			expectedCode: http.StatusBadGateway,
+			expectedErr:  ErrCodeUpstreamInvalidResponse,
+		},
+		{
+			name:   "resolver failure",
+			target: "/iframe",
+			resolver: func(r *http.Request) (*tyrads.AuthenticationRequest, error) {
+				return nil, errors.New("database down")
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedErr:  ErrCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			body := tt.body
+			if body == "" {
+				body = `{"data":{"token":"tok-123"}}`
+			}
+			resolver := tt.resolver
+			if resolver == nil {
+				resolver = staticUser("user123")
+			}
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			var reported error
+			opts := append([]IframeHandlerOptions{func(h *IframeHandler) {
+				h.OnError = func(r *http.Request, err error) { reported = err }
+			}}, tt.opts...)
+			sdk := tt.sdk
+			if sdk == nil {
+				sdk = newTestSdk(t, status, body)
+			}
+			h := NewIframeHandler(sdk, resolver, opts...)
+			if tt.prime {
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, tt.target, nil))
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, tt.target, nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body.String())
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error.Code != tt.expectedErr {
+				t.Errorf("expected error code '%s', got '%s'", tt.expectedErr, resp.Error.Code)
+			}
+			if tt.expectedCode != http.StatusMethodNotAllowed && reported == nil {
+				t.Error("expected OnError to be called")
+			}
+		})
+	}
+}
+
+func TestIframeHandler_ValidationFields(t *testing.T) {
+	resolver := func(r *http.Request) (*tyrads.AuthenticationRequest, error) {
+		return contract.NewAuthenticationRequest("user123", contract.WithAge(-1), contract.WithEmail("invalid")), nil
+	}
+	h := NewIframeHandler(newTestSdk(t, http.StatusOK, `{"data":{"token":"tok-123"}}`), resolver)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/iframe", nil))
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Error.Fields) != 2 {
+		t.Fatalf("expected 2 field errors, got %+v", resp.Error.Fields)
+	}
+	if f := resp.Error.Fields[0]; f.JSONKey != "age" || f.Rule != contract.RuleMin {
+		t.Errorf("expected age min violation, got %+v", f)
+	}
+	if f := resp.Error.Fields[1]; f.JSONKey != "email" || f.Rule != contract.RuleFormat {
+		t.Errorf("expected email format violation, got %+v", f)
+	}
+}
@@ -0,0 +1,29 @@
+// Package signing computes the HMAC signatures of query strings, shared by signed launch
+// URLs and postback callbacks.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+)
+
+// Sign returns the hex encoded HMAC-SHA256, keyed with secret, of the canonical form of
+// values: the sorted, URL-encoded query string without the signatureParam parameter.
+func Sign(values url.Values, signatureParam, secret string) string {
+	canonical := url.Values{}
+	for k, v := range values {
+		if k != signatureParam {
+			canonical[k] = v
+		}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Valid reports whether the signatureParam parameter of values is its signature, in constant time.
+func Valid(values url.Values, signatureParam, secret string) bool {
+	return hmac.Equal([]byte(Sign(values, signatureParam, secret)), []byte(values.Get(signatureParam)))
+}
@@ -0,0 +1,34 @@
+package signing
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	values := url.Values{"b": {"2"}, "a": {"1"}}
+	signature := Sign(values, "sig", "secret")
+	if len(signature) != 64 {
+		t.Fatalf("expected hex SHA-256, got %s", signature)
+	}
+
+	tests := []struct {
+		name     string
+		values   url.Values
+		secret   string
+		expected bool
+	}{
+		{name: "same values signed", values: url.Values{"a": {"1"}, "b": {"2"}, "sig": {signature}}, secret: "secret", expected: true},
+		{name: "tampered value", values: url.Values{"a": {"1"}, "b": {"3"}, "sig": {signature}}, secret: "secret"},
+		{name: "other secret", values: url.Values{"a": {"1"}, "b": {"2"}, "sig": {signature}}, secret: "other"},
+		{name: "missing signature", values: url.Values{"a": {"1"}, "b": {"2"}}, secret: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.values, "sig", tt.secret); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
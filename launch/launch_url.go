@@ -0,0 +1,173 @@
+// Package launch builds HMAC-signed launch URLs.
+//
+// Experimental: signed launch is not part of the documented TyrAds API. URLs built here
+// are only accepted if TyrAds has enabled signed launch for the publisher account.
+package launch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/internal/signing"
+)
+
+// Query parameters reserved by signed launch URLs. All other parameters are profile fields.
+const (
+	ParamApiKey          = "apiKey"
+	ParamPublisherUserID = "publisherUserId"
+	ParamIssuedAt        = "iat"
+	ParamExpiresAt       = "exp"
+	ParamNonce           = "nonce"
+	ParamDeeplinkTo      = "to"
+	ParamSignature       = "sig"
+)
+
+// MaxClockSkew is the tolerance applied to the issued-at timestamp during verification.
+const MaxClockSkew = time.Minute
+
+var (
+	ErrMissingParam     = errors.New("missing launch parameter")
+	ErrInvalidSignature = errors.New("invalid launch signature")
+	ErrExpired          = errors.New("launch url expired")
+	ErrNotYetValid      = errors.New("launch url not yet valid")
+	ErrReservedParam    = errors.New("profile field uses a reserved launch parameter")
+	ErrInvalidValidity  = errors.New("launch url must expire after it is issued")
+)
+
+// Claims holds the data carried by a signed launch URL.
+type Claims struct {
+	ApiKey          string
+	PublisherUserID string
+	IssuedAt        time.Time
+	ExpiresAt       time.Time
+	Nonce           string
+	DeeplinkTo      string
+	Profile         map[string]string
+}
+
+// NewNonce returns a random 128-bit hex encoded nonce.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the canonical form of values,
+// which is the sorted, URL-encoded query string without the signature parameter.
+func Sign(values url.Values, secret string) string {
+	return signing.Sign(values, ParamSignature, secret)
+}
+
+// IsReservedParam reports whether key is one of the reserved query parameters.
+func IsReservedParam(key string) bool {
+	switch key {
+	case ParamApiKey, ParamPublisherUserID, ParamIssuedAt, ParamExpiresAt, ParamNonce, ParamDeeplinkTo, ParamSignature:
+		return true
+	}
+	return false
+}
+
+// BuildUrl returns baseURL with the claims encoded and signed as query parameters. It returns
+// ErrReservedParam if a profile key collides with a reserved parameter, and ErrInvalidValidity
+// unless ExpiresAt is after IssuedAt.
+func BuildUrl(baseURL, secret string, claims Claims) (string, error) {
+	if secret == "" {
+		return "", errors.New("api secret is required to sign launch urls")
+	}
+	if claims.PublisherUserID == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingParam, ParamPublisherUserID)
+	}
+	if claims.Nonce == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingParam, ParamNonce)
+	}
+	if !claims.ExpiresAt.After(claims.IssuedAt) {
+		return "", ErrInvalidValidity
+	}
+	for k := range claims.Profile {
+		if IsReservedParam(k) {
+			return "", fmt.Errorf("%w: %s", ErrReservedParam, k)
+		}
+	}
+
+	values := url.Values{}
+	for k, v := range claims.Profile {
+		values.Set(k, v)
+	}
+	values.Set(ParamApiKey, claims.ApiKey)
+	values.Set(ParamPublisherUserID, claims.PublisherUserID)
+	values.Set(ParamIssuedAt, strconv.FormatInt(claims.IssuedAt.Unix(), 10))
+	values.Set(ParamExpiresAt, strconv.FormatInt(claims.ExpiresAt.Unix(), 10))
+	values.Set(ParamNonce, claims.Nonce)
+	if claims.DeeplinkTo != "" {
+		values.Set(ParamDeeplinkTo, claims.DeeplinkTo)
+	}
+	values.Set(ParamSignature, Sign(values, secret))
+
+	return fmt.Sprintf("%s?%s", baseURL, values.Encode()), nil
+}
+
+// VerifyUrl checks the signature and validity window of a launch URL and returns its claims.
+// It is the reference check for URLs built by BuildUrl, intended for tests.
+func VerifyUrl(rawURL, secret string, now time.Time) (*Claims, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid launch url: %w", err)
+	}
+	values := u.Query()
+
+	for _, param := range []string{ParamPublisherUserID, ParamIssuedAt, ParamExpiresAt, ParamNonce, ParamSignature} {
+		if values.Get(param) == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingParam, param)
+		}
+	}
+
+	if !signing.Valid(values, ParamSignature, secret) {
+		return nil, ErrInvalidSignature
+	}
+
+	issuedAt, err := parseUnix(values.Get(ParamIssuedAt))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ParamIssuedAt, err)
+	}
+	expiresAt, err := parseUnix(values.Get(ParamExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ParamExpiresAt, err)
+	}
+	if issuedAt.After(now.Add(MaxClockSkew)) {
+		return nil, ErrNotYetValid
+	}
+	if !now.Before(expiresAt) {
+		return nil, ErrExpired
+	}
+
+	claims := &Claims{
+		ApiKey:          values.Get(ParamApiKey),
+		PublisherUserID: values.Get(ParamPublisherUserID),
+		IssuedAt:        issuedAt,
+		ExpiresAt:       expiresAt,
+		Nonce:           values.Get(ParamNonce),
+		DeeplinkTo:      values.Get(ParamDeeplinkTo),
+		Profile:         map[string]string{},
+	}
+	for k := range values {
+		if !IsReservedParam(k) {
+			claims.Profile[k] = values.Get(k)
+		}
+	}
+	return claims, nil
+}
+
+func parseUnix(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
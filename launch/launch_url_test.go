@@ -0,0 +1,134 @@
+package launch
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testClaims(now time.Time) Claims {
+	return Claims{
+		ApiKey:          "test-key",
+		PublisherUserID: "user123",
+		IssuedAt:        now,
+		ExpiresAt:       now.Add(5 * time.Minute),
+		Nonce:           "nonce-1",
+		DeeplinkTo:      "offers",
+		Profile:         map[string]string{"age": "25", "sub1": "campaign"},
+	}
+}
+
+func TestBuildUrlAndVerifyUrl(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	launchUrl, err := BuildUrl("https://sdk.tyrads.com", "test-secret", testClaims(now))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(launchUrl, "https://sdk.tyrads.com?") {
+		t.Errorf("unexpected url: %s", launchUrl)
+	}
+
+	claims, err := VerifyUrl(launchUrl, "test-secret", now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.PublisherUserID != "user123" || claims.ApiKey != "test-key" || claims.DeeplinkTo != "offers" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if !claims.ExpiresAt.Equal(now.Add(5 * time.Minute)) {
+		t.Errorf("expected expiry %v, got %v", now.Add(5*time.Minute), claims.ExpiresAt)
+	}
+	if claims.Profile["age"] != "25" || claims.Profile["sub1"] != "campaign" || len(claims.Profile) != 2 {
+		t.Errorf("unexpected profile: %+v", claims.Profile)
+	}
+}
+
+func TestVerifyUrl_Errors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	launchUrl, err := BuildUrl("https://sdk.tyrads.com", "test-secret", testClaims(now))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tampered, _ := url.Parse(launchUrl)
+	q := tampered.Query()
+	q.Set(ParamPublisherUserID, "user456")
+	tampered.RawQuery = q.Encode()
+
+	missing, _ := url.Parse(launchUrl)
+	q = missing.Query()
+	q.Del(ParamNonce)
+	missing.RawQuery = q.Encode()
+
+	tests := []struct {
+		name        string
+		url         string
+		secret      string
+		now         time.Time
+		expectedErr error
+	}{
+		{name: "wrong secret", url: launchUrl, secret: "other", now: now, expectedErr: ErrInvalidSignature},
+		{name: "tampered", url: tampered.String(), secret: "test-secret", now: now, expectedErr: ErrInvalidSignature},
+		{name: "missing nonce", url: missing.String(), secret: "test-secret", now: now, expectedErr: ErrMissingParam},
+		{name: "expired", url: launchUrl, secret: "test-secret", now: now.Add(5 * time.Minute), expectedErr: ErrExpired},
+		{name: "issued in the future", url: launchUrl, secret: "test-secret", now: now.Add(-2 * time.Minute), expectedErr: ErrNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyUrl(tt.url, tt.secret, tt.now)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildUrl_Errors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	if _, err := BuildUrl("https://sdk.tyrads.com", "", testClaims(now)); err == nil {
+		t.Error("expected error for empty secret")
+	}
+
+	claims := testClaims(now)
+	claims.PublisherUserID = ""
+	if _, err := BuildUrl("https://sdk.tyrads.com", "test-secret", claims); !errors.Is(err, ErrMissingParam) {
+		t.Errorf("expected ErrMissingParam, got %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(c *Claims)
+		expectedErr error
+	}{
+		{name: "reserved profile key", modify: func(c *Claims) { c.Profile[ParamDeeplinkTo] = "evil" }, expectedErr: ErrReservedParam},
+		{name: "reserved signature key", modify: func(c *Claims) { c.Profile[ParamSignature] = "forged" }, expectedErr: ErrReservedParam},
+		{name: "zero validity", modify: func(c *Claims) { c.ExpiresAt = c.IssuedAt }, expectedErr: ErrInvalidValidity},
+		{name: "negative validity", modify: func(c *Claims) { c.ExpiresAt = c.IssuedAt.Add(-time.Minute) }, expectedErr: ErrInvalidValidity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := testClaims(now)
+			tt.modify(&claims)
+			if _, err := BuildUrl("https://sdk.tyrads.com", "test-secret", claims); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	a, err := NewNonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := NewNonce()
+	if len(a) != 32 || a == b {
+		t.Errorf("expected distinct 32 character nonces, got %s and %s", a, b)
+	}
+}
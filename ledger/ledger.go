@@ -0,0 +1,246 @@
+// Package ledger records the rewards credited to users, so a postback can be applied
+// exactly once and reversed later.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/postback"
+)
+
+var (
+	// ErrConflict is returned when a transaction ID is credited again with different values,
+	// or reversed for another user than the one it credits.
+	ErrConflict = errors.New("transaction conflicts with a recorded transaction")
+	// ErrInvalidAmount is returned for a postback amount that is not a whole number of minor
+	// units, or a reversal of more than its credit.
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrReversalRejected is returned by Credit, together with the recorded credit, when a
+	// reversal received before the credit is for another user or exceeds it. The reversal
+	// is dropped and the credit kept.
+	ErrReversalRejected = errors.New("pending reversal rejected")
+)
+
+// AmountDecimals is the number of decimals of the postback amounts kept by the ledger.
+// Amounts are recorded as integers in minor units, hundredths of the postback amount, so
+// they add up and compare exactly.
+const AmountDecimals = 2
+
+// EntryType distinguishes credits from their reversals.
+type EntryType string
+
+const (
+	EntryCredit   EntryType = "credit"
+	EntryReversal EntryType = "reversal"
+	// EntryPendingReversal is a reversal received before its credit. It is applied when the
+	// credit is recorded and never counts in Balance or History.
+	EntryPendingReversal EntryType = "pending_reversal"
+)
+
+// Transaction is a reward to credit.
+type Transaction struct {
+	TransactionID string
+	// PublisherUserID is the ID passed to Authenticate.
+	PublisherUserID string
+	// Amount is in minor units, see AmountDecimals.
+	Amount  int64
+	OfferID string
+}
+
+// Reversal reverses a credited transaction, in full or in part.
+type Reversal struct {
+	TransactionID string
+	// PublisherUserID must be the user of the credit.
+	PublisherUserID string
+	// Amount is the reversed amount in minor units, at most the credited amount, or 0 to
+	// reverse the whole credit.
+	Amount int64
+	Reason string
+}
+
+// Entry is a recorded credit or reversal. Reversals carry the negated reversed amount.
+type Entry struct {
+	TransactionID   string
+	PublisherUserID string
+	Type            EntryType
+	Amount          int64
+	OfferID         string
+	Reason          string
+	CreatedAt       time.Time
+}
+
+// Ledger records credits and reversals keyed by transaction ID.
+type Ledger interface {
+	// Credit records tx once. Crediting the same transaction again returns the recorded entry,
+	// or ErrConflict if the user or amount differ. A pending reversal of tx is applied with
+	// the credit; if it is for another user or exceeds the credit, it is dropped and Credit
+	// returns the recorded credit with ErrReversalRejected.
+	Credit(ctx context.Context, tx Transaction) (Entry, error)
+	// Reverse records the reversal of a credited transaction once, for a chargeback or fraud.
+	// Reversing it again with the same user and amount returns the recorded reversal, and
+	// with another user or amount returns ErrConflict. It returns ErrConflict if the user
+	// differs from the credit's and ErrInvalidAmount if the amount exceeds the credit. A
+	// reversal of a transaction not credited yet is recorded as an EntryPendingReversal and
+	// applied by Credit.
+	Reverse(ctx context.Context, rev Reversal) (Entry, error)
+	// Balance returns the sum of the user's entries, in minor units.
+	Balance(ctx context.Context, publisherUserID string) (int64, error)
+	// History returns the user's entries, oldest first.
+	History(ctx context.Context, publisherUserID string) ([]Entry, error)
+}
+
+// RewardFunc returns a postback.RewardFunc crediting verified reward events to l.
+// Events are credited to Event.UserID, the ID passed to Authenticate. ErrReversalRejected
+// is returned, so the Handler reports it to OnError; the credit is recorded, so the retried
+// callback succeeds.
+func RewardFunc(l Ledger) postback.RewardFunc {
+	return func(ctx context.Context, event postback.Event) error {
+		tx, err := TransactionFromEvent(event)
+		if err != nil {
+			return err
+		}
+		_, err = l.Credit(ctx, tx)
+		return err
+	}
+}
+
+// ReversalFunc returns a postback.ReversalFunc reversing the credits of verified reversal
+// events in l. A reversal arriving before its reward is recorded as pending and applied
+// when the reward is credited.
+func ReversalFunc(l Ledger) postback.ReversalFunc {
+	return func(ctx context.Context, event postback.ReversalEvent) error {
+		rev, err := ReversalFromEvent(event)
+		if err != nil {
+			return err
+		}
+		_, err = l.Reverse(ctx, rev)
+		return err
+	}
+}
+
+// TransactionFromEvent converts a reward event to a Transaction. It returns ErrInvalidAmount
+// if the amount has more than AmountDecimals decimals.
+func TransactionFromEvent(event postback.Event) (Transaction, error) {
+	userID := event.UserID
+	if userID == "" {
+		userID = event.PublisherUserID
+	}
+	amount, err := MinorUnits(event.Amount)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return Transaction{
+		TransactionID:   event.TransactionID,
+		PublisherUserID: userID,
+		Amount:          amount,
+		OfferID:         event.OfferID,
+	}, nil
+}
+
+// ReversalFromEvent converts a reversal event to a Reversal. It returns ErrInvalidAmount if
+// the amount has more than AmountDecimals decimals.
+func ReversalFromEvent(event postback.ReversalEvent) (Reversal, error) {
+	userID := event.UserID
+	if userID == "" {
+		userID = event.PublisherUserID
+	}
+	amount, err := MinorUnits(event.Amount)
+	if err != nil {
+		return Reversal{}, err
+	}
+	return Reversal{
+		TransactionID:   event.TransactionID,
+		PublisherUserID: userID,
+		Amount:          amount,
+		Reason:          event.Reason,
+	}, nil
+}
+
+// MinorUnits converts a postback amount to minor units. It returns ErrInvalidAmount if the
+// amount is NaN, infinite or negative, has more than AmountDecimals decimals or does not fit
+// in an int64.
+func MinorUnits(amount float64) (int64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+	scaled := amount * math.Pow10(AmountDecimals)
+	units := math.Round(scaled)
+	if math.Abs(scaled-units) > 1e-6 || units >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+	return int64(units), nil
+}
+
+// sameTransaction reports whether a recorded credit matches a repeated transaction.
+func sameTransaction(entry Entry, tx Transaction) bool {
+	return entry.PublisherUserID == tx.PublisherUserID && entry.Amount == tx.Amount
+}
+
+// sameReversal reports whether a recorded reversal or pending reversal matches a repeated
+// reversal. credited is the amount of the credit, or 0 for a pending reversal.
+func sameReversal(entry Entry, rev Reversal, credited int64) bool {
+	amount := rev.Amount
+	if amount == 0 {
+		amount = credited
+	}
+	return entry.PublisherUserID == rev.PublisherUserID && -entry.Amount == amount
+}
+
+// reversalConflict returns the ErrConflict of a reversal not matching the recorded one.
+func reversalConflict(rev Reversal) error {
+	return fmt.Errorf("%w: %s already reversed with another user or amount", ErrConflict, rev.TransactionID)
+}
+
+// rejectedReversal returns the ErrReversalRejected of a pending reversal that could not be applied.
+func rejectedReversal(pending Entry, err error) error {
+	return fmt.Errorf("%w: reversal of %s for %s: %v", ErrReversalRejected, pending.TransactionID, pending.PublisherUserID, err)
+}
+
+// pendingEntry returns the EntryPendingReversal recording rev.
+func pendingEntry(rev Reversal, now time.Time) Entry {
+	return Entry{
+		TransactionID:   rev.TransactionID,
+		PublisherUserID: rev.PublisherUserID,
+		Type:            EntryPendingReversal,
+		Amount:          -rev.Amount,
+		Reason:          rev.Reason,
+		CreatedAt:       now,
+	}
+}
+
+// pendingReversal returns the Reversal recorded by a pending entry.
+func pendingReversal(entry Entry) Reversal {
+	return Reversal{
+		TransactionID:   entry.TransactionID,
+		PublisherUserID: entry.PublisherUserID,
+		Amount:          -entry.Amount,
+		Reason:          entry.Reason,
+	}
+}
+
+// reversalEntry returns the entry reversing credit by rev, or ErrConflict or ErrInvalidAmount.
+func reversalEntry(credit Entry, rev Reversal, now time.Time) (Entry, error) {
+	if rev.PublisherUserID != credit.PublisherUserID {
+		return Entry{}, fmt.Errorf("%w: %s reversed for another user", ErrConflict, rev.TransactionID)
+	}
+	amount := rev.Amount
+	if amount == 0 {
+		amount = credit.Amount
+	}
+	if amount < 0 || amount > credit.Amount {
+		return Entry{}, fmt.Errorf("%w: reversal of %d exceeds credit of %d for %s", ErrInvalidAmount, amount, credit.Amount, rev.TransactionID)
+	}
+	return Entry{
+		TransactionID:   credit.TransactionID,
+		PublisherUserID: credit.PublisherUserID,
+		Type:            EntryReversal,
+		Amount:          -amount,
+		OfferID:         credit.OfferID,
+		Reason:          rev.Reason,
+		CreatedAt:       now,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/postback"
+)
+
+func TestTransactionFromEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		event          postback.Event
+		expectedUserID string
+		expectedAmount int64
+		expectedErr    error
+	}{
+		{name: "mapped user", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "ext-1", UserID: "user-1", Amount: 10}, expectedUserID: "user-1", expectedAmount: 1000},
+		{name: "unmapped user", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10}, expectedUserID: "user-1", expectedAmount: 1000},
+		{name: "decimal amount", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 0.1 + 0.2}, expectedUserID: "user-1", expectedAmount: 30},
+		{name: "negative amount", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: -19.99}, expectedErr: ErrInvalidAmount},
+		{name: "too many decimals", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10.005}, expectedErr: ErrInvalidAmount},
+		{name: "too large", event: postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 1e20}, expectedErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := TransactionFromEvent(tt.event)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tx.PublisherUserID != tt.expectedUserID {
+				t.Errorf("expected user %q, got %q", tt.expectedUserID, tx.PublisherUserID)
+			}
+			if err == nil && (tx.TransactionID != tt.event.TransactionID || tx.Amount != tt.expectedAmount) {
+				t.Errorf("expected transaction %q of %d, got %q of %d", tt.event.TransactionID, tt.expectedAmount, tx.TransactionID, tx.Amount)
+			}
+		})
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		expected    int64
+		expectedErr error
+	}{
+		{name: "zero", amount: 0, expected: 0},
+		{name: "whole", amount: 10, expected: 1000},
+		{name: "cents", amount: 0.07, expected: 7},
+		{name: "largest exact", amount: 1e16, expected: 1e18},
+		{name: "too many decimals", amount: 0.001, expectedErr: ErrInvalidAmount},
+		{name: "negative", amount: -1, expectedErr: ErrInvalidAmount},
+		{name: "NaN", amount: math.NaN(), expectedErr: ErrInvalidAmount},
+		{name: "positive infinity", amount: math.Inf(1), expectedErr: ErrInvalidAmount},
+		{name: "negative infinity", amount: math.Inf(-1), expectedErr: ErrInvalidAmount},
+		{name: "out of range", amount: math.MaxInt64 / 10, expectedErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units, err := MinorUnits(tt.amount)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if units != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, units)
+			}
+		})
+	}
+}
+
+func TestRewardFunc(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLedger()
+	reward := RewardFunc(l)
+	event := postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", UserID: "user-1", Amount: 10}
+
+	for i := 0; i < 2; i++ {
+		if err := reward(ctx, event); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if balance, _ := l.Balance(ctx, "user-1"); balance != 1000 {
+		t.Errorf("expected balance 1000 after a duplicate postback, got %v", balance)
+	}
+
+	event.Amount = 20
+	if err := reward(ctx, event); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestReversalFunc(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLedger()
+	if _, err := l.Credit(ctx, Transaction{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 1000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reverse := ReversalFunc(l)
+
+	tests := []struct {
+		name            string
+		event           postback.ReversalEvent
+		expectedErr     error
+		expectedBalance int64
+	}{
+		{name: "another user", event: postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "ext-2", UserID: "user-2"}, expectedErr: ErrConflict, expectedBalance: 1000},
+		{name: "more than credited", event: postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10.01}, expectedErr: ErrInvalidAmount, expectedBalance: 1000},
+		{name: "too many decimals", event: postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 2.505}, expectedErr: ErrInvalidAmount, expectedBalance: 1000},
+		{name: "partial amount", event: postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 2.5}, expectedBalance: 750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := reverse(ctx, tt.event); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if balance, _ := l.Balance(ctx, "user-1"); balance != tt.expectedBalance {
+				t.Errorf("expected balance %d, got %d", tt.expectedBalance, balance)
+			}
+		})
+	}
+}
+
+func TestReversalFunc_ReversalBeforeCredit(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLedger()
+	h := postback.NewHandler("test-secret", RewardFunc(l), func(h *postback.Handler) {
+		h.OnReversal = ReversalFunc(l)
+	})
+	now := time.Unix(time.Now().Unix(), 0)
+	reward := postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10, Timestamp: now}
+	reversal := postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "user-1", Reason: "chargeback", Timestamp: now.Add(time.Minute)}
+
+	steps := []struct {
+		name            string
+		values          url.Values
+		expectedCode    int
+		expectedBalance int64
+	}{
+		{name: "reversal before credit", values: reversal.SignedValues("test-secret"), expectedCode: http.StatusOK},
+		{name: "credit", values: reward.SignedValues("test-secret"), expectedCode: http.StatusOK},
+		{name: "retried reversal", values: reversal.SignedValues("test-secret"), expectedCode: http.StatusOK},
+		{name: "duplicate reversal", values: reversal.SignedValues("test-secret"), expectedCode: http.StatusOK},
+		{name: "duplicate credit", values: reward.SignedValues("test-secret"), expectedCode: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/postback?"+step.values.Encode(), nil))
+		if rec.Code != step.expectedCode {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.expectedCode, rec.Code)
+		}
+		if balance, _ := l.Balance(ctx, "user-1"); balance != step.expectedBalance {
+			t.Errorf("%s: expected balance %v, got %v", step.name, step.expectedBalance, balance)
+		}
+	}
+
+	history, _ := l.History(ctx, "user-1")
+	if len(history) != 2 || history[1].Type != EntryReversal || history[1].Reason != "chargeback" {
+		t.Errorf("expected a credit and its chargeback reversal, got %+v", history)
+	}
+}
+
+func TestRewardFunc_RejectedReversal(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLedger()
+	var reported []error
+	h := postback.NewHandler("test-secret", RewardFunc(l), func(h *postback.Handler) {
+		h.OnReversal = ReversalFunc(l)
+		h.OnError = func(r *http.Request, err error) { reported = append(reported, err) }
+	})
+	now := time.Unix(time.Now().Unix(), 0)
+	reward := postback.Event{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10, Timestamp: now}
+	reversal := postback.ReversalEvent{TransactionID: "tx-1", PublisherUserID: "user-2", Reason: "chargeback", Timestamp: now}
+
+	steps := []struct {
+		name         string
+		values       url.Values
+		expectedCode int
+	}{
+		{name: "reversal for another user before credit", values: reversal.SignedValues("test-secret"), expectedCode: http.StatusOK},
+		{name: "credit", values: reward.SignedValues("test-secret"), expectedCode: http.StatusInternalServerError},
+		{name: "retried credit", values: reward.SignedValues("test-secret"), expectedCode: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/postback?"+step.values.Encode(), nil))
+		if rec.Code != step.expectedCode {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.expectedCode, rec.Code)
+		}
+	}
+
+	if balance, _ := l.Balance(ctx, "user-1"); balance != 1000 {
+		t.Errorf("expected the credit to be recorded, got balance %d", balance)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrReversalRejected) {
+		t.Errorf("expected the rejected reversal to be reported once, got %v", reported)
+	}
+}
@@ -0,0 +1,212 @@
+// Package ledgertest checks that a ledger.Ledger implementation behaves like the ones in package ledger.
+package ledgertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/ledger"
+)
+
+// Run checks the behaviour every ledger.Ledger implementation shares against an empty l.
+// Entries of l must be stamped by an increasing clock, such as Clock.
+func Run(t *testing.T, l ledger.Ledger) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		run           func() (ledger.Entry, error)
+		expectedErr   error
+		expectedEntry ledger.Entry
+	}{
+		{
+			name: "credit",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10, OfferID: "offer-1"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-1", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 10, OfferID: "offer-1"},
+		},
+		{
+			name: "duplicate credit",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10, OfferID: "offer-1"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-1", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 10, OfferID: "offer-1"},
+		},
+		{
+			name: "conflicting credit",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-1", PublisherUserID: "user-2", Amount: 10})
+			},
+			expectedErr: ledger.ErrConflict,
+		},
+		{
+			name: "second credit",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-2", PublisherUserID: "user-1", Amount: 5})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-2", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 5},
+		},
+		{
+			name: "reverse",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-1", PublisherUserID: "user-1", Reason: "chargeback"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-1", PublisherUserID: "user-1", Type: ledger.EntryReversal, Amount: -10, OfferID: "offer-1", Reason: "chargeback"},
+		},
+		{
+			name: "duplicate reverse",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-1", PublisherUserID: "user-1", Reason: "fraud"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-1", PublisherUserID: "user-1", Type: ledger.EntryReversal, Amount: -10, OfferID: "offer-1", Reason: "chargeback"},
+		},
+		{
+			name: "reverse again with another amount",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 5})
+			},
+			expectedErr: ledger.ErrConflict,
+		},
+		{
+			name: "reverse again for another user",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-1", PublisherUserID: "user-2"})
+			},
+			expectedErr: ledger.ErrConflict,
+		},
+		{
+			name: "reverse for another user",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-2", PublisherUserID: "user-2", Reason: "chargeback"})
+			},
+			expectedErr: ledger.ErrConflict,
+		},
+		{
+			name: "reverse more than credited",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-2", PublisherUserID: "user-1", Amount: 6})
+			},
+			expectedErr: ledger.ErrInvalidAmount,
+		},
+		{
+			name: "partial reverse",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-2", PublisherUserID: "user-1", Amount: 2, Reason: "partial refund"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-2", PublisherUserID: "user-1", Type: ledger.EntryReversal, Amount: -2, Reason: "partial refund"},
+		},
+		{
+			name: "reverse before credit",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-3", PublisherUserID: "user-1", Reason: "fraud"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-3", PublisherUserID: "user-1", Type: ledger.EntryPendingReversal, Reason: "fraud"},
+		},
+		{
+			name: "duplicate reverse before credit",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-3", PublisherUserID: "user-1", Reason: "chargeback"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-3", PublisherUserID: "user-1", Type: ledger.EntryPendingReversal, Reason: "fraud"},
+		},
+		{
+			name: "reverse before credit again with another amount",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-3", PublisherUserID: "user-1", Amount: 3})
+			},
+			expectedErr: ledger.ErrConflict,
+		},
+		{
+			name: "credit with a pending reversal",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-3", PublisherUserID: "user-1", Amount: 7})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-3", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 7},
+		},
+		{
+			name: "reverse after the pending reversal was applied",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-3", PublisherUserID: "user-1", Reason: "chargeback"})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-3", PublisherUserID: "user-1", Type: ledger.EntryReversal, Amount: -7, Reason: "fraud"},
+		},
+		{
+			name: "reverse before credit for another user",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-4", PublisherUserID: "user-2", Amount: 1})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-4", PublisherUserID: "user-2", Type: ledger.EntryPendingReversal, Amount: -1},
+		},
+		{
+			name: "credit with a pending reversal for another user",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-4", PublisherUserID: "user-1", Amount: 1})
+			},
+			expectedErr:   ledger.ErrReversalRejected,
+			expectedEntry: ledger.Entry{TransactionID: "tx-4", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 1},
+		},
+		{
+			name: "credit again after the pending reversal was rejected",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-4", PublisherUserID: "user-1", Amount: 1})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-4", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 1},
+		},
+		{
+			name: "reverse before credit exceeding the credit",
+			run: func() (ledger.Entry, error) {
+				return l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-5", PublisherUserID: "user-1", Amount: 9})
+			},
+			expectedEntry: ledger.Entry{TransactionID: "tx-5", PublisherUserID: "user-1", Type: ledger.EntryPendingReversal, Amount: -9},
+		},
+		{
+			name: "credit with a pending reversal exceeding it",
+			run: func() (ledger.Entry, error) {
+				return l.Credit(ctx, ledger.Transaction{TransactionID: "tx-5", PublisherUserID: "user-1", Amount: 4})
+			},
+			expectedErr:   ledger.ErrReversalRejected,
+			expectedEntry: ledger.Entry{TransactionID: "tx-5", PublisherUserID: "user-1", Type: ledger.EntryCredit, Amount: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := tt.run()
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			entry.CreatedAt = time.Time{}
+			if entry != tt.expectedEntry {
+				t.Errorf("expected entry %+v, got %+v", tt.expectedEntry, entry)
+			}
+		})
+	}
+
+	if balance, _ := l.Balance(ctx, "user-1"); balance != 8 {
+		t.Errorf("expected balance 8, got %v", balance)
+	}
+	if balance, _ := l.Balance(ctx, "user-2"); balance != 0 {
+		t.Errorf("expected pending reversals not to count, got balance %v", balance)
+	}
+	history, _ := l.History(ctx, "user-1")
+	if len(history) != 8 {
+		t.Fatalf("expected 8 entries, got %+v", history)
+	}
+	for i := 1; i < len(history); i++ {
+		if !history[i].CreatedAt.After(history[i-1].CreatedAt) {
+			t.Errorf("expected history oldest first, got %v before %v", history[i-1].CreatedAt, history[i].CreatedAt)
+		}
+	}
+}
+
+// Clock returns a clock advancing one second per call, starting on 1 January 2024 UTC.
+func Clock() func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
@@ -0,0 +1,13 @@
+package ledgertest
+
+import (
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/ledger"
+)
+
+func TestRun_MemoryLedger(t *testing.T) {
+	l := ledger.NewMemoryLedger()
+	l.Now = Clock()
+	Run(t, l)
+}
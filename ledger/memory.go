@@ -0,0 +1,114 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryLedger is a Ledger kept in memory, for tests and prototypes.
+type MemoryLedger struct {
+	mu        sync.Mutex
+	credits   map[string]Entry
+	reversals map[string]Entry
+	pending   map[string]Entry
+	entries   map[string][]Entry
+
+	// Now stamps new entries. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewMemoryLedger creates an empty MemoryLedger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		credits:   map[string]Entry{},
+		reversals: map[string]Entry{},
+		pending:   map[string]Entry{},
+		entries:   map[string][]Entry{},
+		Now:       time.Now,
+	}
+}
+
+func (l *MemoryLedger) Credit(ctx context.Context, tx Transaction) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.credits[tx.TransactionID]; ok {
+		if !sameTransaction(entry, tx) {
+			return Entry{}, fmt.Errorf("%w: %s", ErrConflict, tx.TransactionID)
+		}
+		return entry, nil
+	}
+	entry := Entry{
+		TransactionID:   tx.TransactionID,
+		PublisherUserID: tx.PublisherUserID,
+		Type:            EntryCredit,
+		Amount:          tx.Amount,
+		OfferID:         tx.OfferID,
+		CreatedAt:       l.Now(),
+	}
+	entries := []Entry{entry}
+	var rejected error
+	if pending, ok := l.pending[tx.TransactionID]; ok {
+		delete(l.pending, tx.TransactionID)
+		if reversal, err := reversalEntry(entry, pendingReversal(pending), l.Now()); err != nil {
+			rejected = rejectedReversal(pending, err)
+		} else {
+			l.reversals[tx.TransactionID] = reversal
+			entries = append(entries, reversal)
+		}
+	}
+	l.credits[tx.TransactionID] = entry
+	l.entries[tx.PublisherUserID] = append(l.entries[tx.PublisherUserID], entries...)
+	return entry, rejected
+}
+
+func (l *MemoryLedger) Reverse(ctx context.Context, rev Reversal) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.reversals[rev.TransactionID]; ok {
+		if !sameReversal(entry, rev, l.credits[rev.TransactionID].Amount) {
+			return Entry{}, reversalConflict(rev)
+		}
+		return entry, nil
+	}
+	credit, ok := l.credits[rev.TransactionID]
+	if !ok {
+		if entry, ok := l.pending[rev.TransactionID]; ok {
+			if !sameReversal(entry, rev, 0) {
+				return Entry{}, reversalConflict(rev)
+			}
+			return entry, nil
+		}
+		entry := pendingEntry(rev, l.Now())
+		l.pending[rev.TransactionID] = entry
+		return entry, nil
+	}
+	entry, err := reversalEntry(credit, rev, l.Now())
+	if err != nil {
+		return Entry{}, err
+	}
+	l.reversals[rev.TransactionID] = entry
+	l.entries[credit.PublisherUserID] = append(l.entries[credit.PublisherUserID], entry)
+	return entry, nil
+}
+
+func (l *MemoryLedger) Balance(ctx context.Context, publisherUserID string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var balance int64
+	for _, entry := range l.entries[publisherUserID] {
+		balance += entry.Amount
+	}
+	return balance, nil
+}
+
+func (l *MemoryLedger) History(ctx context.Context, publisherUserID string) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Entry(nil), l.entries[publisherUserID]...), nil
+}
@@ -0,0 +1,26 @@
+package ledger
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestMemoryLedger_ConcurrentCredit(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLedger()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Credit(ctx, Transaction{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10})
+		}()
+	}
+	wg.Wait()
+
+	if balance, _ := l.Balance(ctx, "user-1"); balance != 10 {
+		t.Errorf("expected balance 10, got %v", balance)
+	}
+}
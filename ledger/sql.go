@@ -0,0 +1,297 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect selects the SQL flavour of a SQLLedger.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// Tables created by SQLLedger.Migrate.
+const (
+	EntriesTable    = "tyrads_ledger_entries"
+	MigrationsTable = "tyrads_ledger_migrations"
+)
+
+// migrations are applied in order by Migrate; each one is recorded in MigrationsTable.
+// Amounts are integers in minor units, and created_at and applied_at hold Unix microseconds.
+func migrations() [][]string {
+	return [][]string{
+		{
+			`CREATE TABLE IF NOT EXISTS ` + EntriesTable + ` (
+	transaction_id TEXT NOT NULL,
+	entry_type TEXT NOT NULL,
+	publisher_user_id TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	offer_id TEXT NOT NULL DEFAULT '',
+	reason TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL,
+	PRIMARY KEY (transaction_id, entry_type)
+)`,
+			`CREATE INDEX IF NOT EXISTS ` + EntriesTable + `_user_idx ON ` + EntriesTable + ` (publisher_user_id, created_at)`,
+		},
+	}
+}
+
+const entryColumns = "transaction_id, entry_type, publisher_user_id, amount, offer_id, reason, created_at"
+
+// SQLLedger is a Ledger stored with database/sql in Postgres or SQLite. Credits and
+// reversals are unique per transaction ID, so concurrent deliveries are applied once.
+// On Postgres a delivery may fail with a serialization error when it races another one
+// for the same transaction; the sender retries it.
+// The driver is chosen by the caller, such as pgx or modernc.org/sqlite.
+type SQLLedger struct {
+	db      *sql.DB
+	dialect Dialect
+
+	// Now stamps new entries. Defaults to time.Now.
+	Now func() time.Time
+}
+
+type SQLLedgerOptions func(*SQLLedger)
+
+// NewSQLLedger creates a SQLLedger. Call Migrate before using it.
+func NewSQLLedger(db *sql.DB, dialect Dialect, opts ...SQLLedgerOptions) *SQLLedger {
+	l := &SQLLedger{
+		db:      db,
+		dialect: dialect,
+		Now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Migrate creates or upgrades the ledger tables. Each pending migration runs in its own transaction.
+func (l *SQLLedger) Migrate(ctx context.Context) error {
+	if _, err := l.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+MigrationsTable+` (
+	version INTEGER PRIMARY KEY,
+	applied_at BIGINT NOT NULL
+)`); err != nil {
+		return fmt.Errorf("create migrations table: %w", err)
+	}
+
+	var current int
+	if err := l.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM `+MigrationsTable).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for i, statements := range migrations() {
+		version := i + 1
+		if version <= current {
+			continue
+		}
+		if err := l.inTx(ctx, func(tx *sql.Tx) error {
+			for _, statement := range statements {
+				if _, err := tx.ExecContext(ctx, statement); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, l.rebind(`INSERT INTO `+MigrationsTable+` (version, applied_at) VALUES (?, ?)`), version, l.Now().UnixMicro())
+			return err
+		}); err != nil {
+			return fmt.Errorf("apply migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func (l *SQLLedger) Credit(ctx context.Context, tx Transaction) (Entry, error) {
+	var entry Entry
+	var rejected error
+	err := l.inTx(ctx, func(sqlTx *sql.Tx) error {
+		if err := l.insert(ctx, sqlTx, Entry{
+			TransactionID:   tx.TransactionID,
+			PublisherUserID: tx.PublisherUserID,
+			Type:            EntryCredit,
+			Amount:          tx.Amount,
+			OfferID:         tx.OfferID,
+			CreatedAt:       l.Now(),
+		}); err != nil {
+			return err
+		}
+		var err error
+		entry, err = l.find(ctx, sqlTx, tx.TransactionID, EntryCredit)
+		if err != nil {
+			return err
+		}
+		if !sameTransaction(entry, tx) {
+			return fmt.Errorf("%w: %s", ErrConflict, tx.TransactionID)
+		}
+		rejected, err = l.applyPending(ctx, sqlTx, entry)
+		return err
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, rejected
+}
+
+func (l *SQLLedger) Reverse(ctx context.Context, rev Reversal) (Entry, error) {
+	var entry Entry
+	err := l.inTx(ctx, func(sqlTx *sql.Tx) error {
+		existing, err := l.find(ctx, sqlTx, rev.TransactionID, EntryReversal)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		found := err == nil
+		credit, err := l.find(ctx, sqlTx, rev.TransactionID, EntryCredit)
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := l.insert(ctx, sqlTx, pendingEntry(rev, l.Now())); err != nil {
+				return err
+			}
+			entry, err = l.find(ctx, sqlTx, rev.TransactionID, EntryPendingReversal)
+			if err == nil && !sameReversal(entry, rev, 0) {
+				return reversalConflict(rev)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if found {
+			if !sameReversal(existing, rev, credit.Amount) {
+				return reversalConflict(rev)
+			}
+			entry = existing
+			return nil
+		}
+		reversal, err := reversalEntry(credit, rev, l.Now())
+		if err != nil {
+			return err
+		}
+		if err := l.insert(ctx, sqlTx, reversal); err != nil {
+			return err
+		}
+		entry, err = l.find(ctx, sqlTx, rev.TransactionID, EntryReversal)
+		return err
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// applyPending records the reversal of credit received before it, if any, and removes the
+// pending reversal. A pending reversal that does not fit the credit is removed and returned
+// as rejected.
+func (l *SQLLedger) applyPending(ctx context.Context, tx *sql.Tx, credit Entry) (rejected, err error) {
+	pending, err := l.find(ctx, tx, credit.TransactionID, EntryPendingReversal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, l.rebind(`DELETE FROM `+EntriesTable+` WHERE transaction_id = ? AND entry_type = ?`), credit.TransactionID, string(EntryPendingReversal)); err != nil {
+		return nil, err
+	}
+	reversal, err := reversalEntry(credit, pendingReversal(pending), l.Now())
+	if err != nil {
+		return rejectedReversal(pending, err), nil
+	}
+	return nil, l.insert(ctx, tx, reversal)
+}
+
+func (l *SQLLedger) Balance(ctx context.Context, publisherUserID string) (int64, error) {
+	var balance int64
+	err := l.db.QueryRowContext(ctx, l.rebind(`SELECT COALESCE(SUM(amount), 0) FROM `+EntriesTable+` WHERE publisher_user_id = ? AND entry_type IN (?, ?)`), publisherUserID, string(EntryCredit), string(EntryReversal)).Scan(&balance)
+	return balance, err
+}
+
+func (l *SQLLedger) History(ctx context.Context, publisherUserID string) ([]Entry, error) {
+	rows, err := l.db.QueryContext(ctx, l.rebind(`SELECT `+entryColumns+` FROM `+EntriesTable+` WHERE publisher_user_id = ? AND entry_type IN (?, ?) ORDER BY created_at, entry_type`), publisherUserID, string(EntryCredit), string(EntryReversal))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// insert adds entry unless an entry of the same transaction and type exists.
+func (l *SQLLedger) insert(ctx context.Context, tx *sql.Tx, entry Entry) error {
+	_, err := tx.ExecContext(ctx, l.rebind(`INSERT INTO `+EntriesTable+` (`+entryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (transaction_id, entry_type) DO NOTHING`),
+		entry.TransactionID, string(entry.Type), entry.PublisherUserID, entry.Amount, entry.OfferID, entry.Reason, entry.CreatedAt.UnixMicro())
+	return err
+}
+
+// find returns the entry of a transaction and type, or sql.ErrNoRows.
+func (l *SQLLedger) find(ctx context.Context, tx *sql.Tx, transactionID string, entryType EntryType) (Entry, error) {
+	row := tx.QueryRowContext(ctx, l.rebind(`SELECT `+entryColumns+` FROM `+EntriesTable+` WHERE transaction_id = ? AND entry_type = ?`), transactionID, string(entryType))
+	return scanEntry(row)
+}
+
+// inTx runs fn in a transaction. Postgres transactions are serializable, so a credit and a
+// reversal received at once cannot both miss each other; SQLite serializes writes anyway.
+func (l *SQLLedger) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	var opts *sql.TxOptions
+	if l.dialect == DialectPostgres {
+		opts = &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+	tx, err := l.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// rebind replaces the ? placeholders of query with $1, $2... for Postgres.
+func (l *SQLLedger) rebind(query string) string {
+	if l.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(s scanner) (Entry, error) {
+	var entry Entry
+	var entryType string
+	var createdAt int64
+	if err := s.Scan(&entry.TransactionID, &entryType, &entry.PublisherUserID, &entry.Amount, &entry.OfferID, &entry.Reason, &createdAt); err != nil {
+		return Entry{}, err
+	}
+	entry.Type = EntryType(entryType)
+	entry.CreatedAt = time.UnixMicro(createdAt)
+	return entry, nil
+}
@@ -0,0 +1,25 @@
+package ledger
+
+import "testing"
+
+func TestSQLLedger_Rebind(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		query    string
+		expected string
+	}{
+		{name: "postgres", dialect: DialectPostgres, query: "VALUES (?, ?, ?)", expected: "VALUES ($1, $2, $3)"},
+		{name: "sqlite", dialect: DialectSQLite, query: "VALUES (?, ?, ?)", expected: "VALUES (?, ?, ?)"},
+		{name: "no placeholders", dialect: DialectPostgres, query: "SELECT 1", expected: "SELECT 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewSQLLedger(nil, tt.dialect)
+			if got := l.rebind(tt.query); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package sqlitetest runs the ledger tests against SQLite. It is a separate module so the
+// SDK does not depend on a SQLite driver; run it with make test-sqlite.
+package sqlitetest
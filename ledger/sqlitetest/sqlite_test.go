@@ -0,0 +1,84 @@
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/ledger"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/ledger/ledgertest"
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLLedger(t *testing.T) {
+	ctx := context.Background()
+	db, l := newLedger(t)
+	if err := l.Migrate(ctx); err != nil {
+		t.Fatalf("expected migrating again to be a no-op, got %v", err)
+	}
+	ledgertest.Run(t, l)
+
+	var version, appliedAt int64
+	if err := db.QueryRowContext(ctx, `SELECT version, applied_at FROM `+ledger.MigrationsTable).Scan(&version, &appliedAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 1 || appliedAt < 1e15 {
+		t.Errorf("expected version 1 applied at Unix microseconds, got %d at %d", version, appliedAt)
+	}
+}
+
+func TestSQLLedger_ExactAmounts(t *testing.T) {
+	ctx := context.Background()
+	_, l := newLedger(t)
+
+	const large = 1<<53 + 1
+	for i, amount := range []int64{large, 1, -2} {
+		if _, err := l.Credit(ctx, ledger.Transaction{TransactionID: string(rune('a' + i)), PublisherUserID: "user-1", Amount: amount}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if balance, _ := l.Balance(ctx, "user-1"); balance != large-1 {
+		t.Errorf("expected balance %d, got %d", int64(large-1), balance)
+	}
+	if _, err := l.Credit(ctx, ledger.Transaction{TransactionID: "a", PublisherUserID: "user-1", Amount: large - 1}); !errors.Is(err, ledger.ErrConflict) {
+		t.Errorf("expected an amount off by one minor unit to conflict, got %v", err)
+	}
+}
+
+func TestSQLLedger_Rollback(t *testing.T) {
+	ctx := context.Background()
+	db, l := newLedger(t)
+
+	if _, err := l.Reverse(ctx, ledger.Reversal{TransactionID: "tx-1", PublisherUserID: "user-1", Reason: "fraud"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TRIGGER reject_reversal BEFORE INSERT ON `+ledger.EntriesTable+`
+WHEN NEW.entry_type = 'reversal' BEGIN SELECT RAISE(ABORT, 'reversal rejected'); END`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := l.Credit(ctx, ledger.Transaction{TransactionID: "tx-1", PublisherUserID: "user-1", Amount: 10}); err == nil {
+		t.Fatal("expected the failed reversal to be reported")
+	}
+	if history, _ := l.History(ctx, "user-1"); len(history) != 0 {
+		t.Errorf("expected the credit to be rolled back with its reversal, got %+v", history)
+	}
+}
+
+func newLedger(t *testing.T) (*sql.DB, *ledger.SQLLedger) {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	l := ledger.NewSQLLedger(db, ledger.DialectSQLite)
+	l.Now = ledgertest.Clock()
+	if err := l.Migrate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return db, l
+}
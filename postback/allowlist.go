@@ -0,0 +1,210 @@
+package postback
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var (
+	ErrInvalidCIDR  = errors.New("invalid CIDR")
+	ErrIPNotAllowed = errors.New("postback source IP not allowed")
+	// ErrNoClientIP is reported when a trusted proxy forwards a request whose X-Forwarded-For
+	// header holds no address outside the trusted proxies.
+	ErrNoClientIP = errors.New("no client address in X-Forwarded-For")
+)
+
+// IPRejectedError is reported for every request rejected by an IPAllowlist.
+type IPRejectedError struct {
+	// IP is the resolved client IP, empty when it could not be determined.
+	IP string
+	// RemoteAddr is the address of the peer connection.
+	RemoteAddr string
+	// ForwardedFor is the X-Forwarded-For header, when it was honoured.
+	ForwardedFor string
+	// Err is the reason the client IP could not be determined, if any.
+	Err error
+}
+
+func (e *IPRejectedError) Error() string {
+	ip := e.IP
+	if e.Err != nil {
+		ip = e.Err.Error()
+	}
+	msg := fmt.Sprintf("%s: %s (remote %s", ErrIPNotAllowed, ip, e.RemoteAddr)
+	if e.ForwardedFor != "" {
+		msg += fmt.Sprintf(", forwarded for %q", e.ForwardedFor)
+	}
+	return msg + ")"
+}
+
+func (e *IPRejectedError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrIPNotAllowed}
+	}
+	return []error{ErrIPNotAllowed, e.Err}
+}
+
+// IPAllowlist is an http middleware accepting callbacks only from allowed source IPs, such
+// as the ranges published by TyrAds. The X-Forwarded-For header is only honoured when the
+// peer connection comes from a trusted proxy, so clients cannot spoof their address.
+//
+//	allowlist, err := postback.NewIPAllowlist(tyradsRanges, postback.WithTrustedProxies("10.0.0.0/8"))
+//	http.Handle("/postback", allowlist.Middleware(postback.NewHandler(secret, onReward)))
+type IPAllowlist struct {
+	allowed        []netip.Prefix
+	trustedProxies []netip.Prefix
+	trustedCIDRs   []string
+
+	// OnReject, when set, is called with an *IPRejectedError for every rejected request.
+	// Rejections are not logged unless it is set, for example to LogRejections(nil).
+	OnReject func(r *http.Request, err error)
+}
+
+// LogRejections returns a hook for IPAllowlist.OnReject or Handler.OnError that logs every
+// rejected request with logger, or with the standard logger when logger is nil.
+func LogRejections(logger *log.Logger) func(r *http.Request, err error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(r *http.Request, err error) {
+		logger.Printf("tyrads: rejected postback %s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+type IPAllowlistOptions func(*IPAllowlist)
+
+// WithTrustedProxies sets the CIDR ranges or IPs of the reverse proxies in front of the
+// callback endpoint, whose X-Forwarded-For header is honoured.
+func WithTrustedProxies(cidrs ...string) IPAllowlistOptions {
+	return func(a *IPAllowlist) {
+		a.trustedCIDRs = append(a.trustedCIDRs, cidrs...)
+	}
+}
+
+// NewIPAllowlist creates an IPAllowlist accepting the given CIDR ranges or single IPs.
+// An empty allowlist rejects every request. It returns ErrInvalidCIDR for an unparsable range.
+func NewIPAllowlist(allowedCIDRs []string, opts ...IPAllowlistOptions) (*IPAllowlist, error) {
+	a := &IPAllowlist{}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	var err error
+	if a.allowed, err = parsePrefixes(allowedCIDRs); err != nil {
+		return nil, err
+	}
+	if a.trustedProxies, err = parsePrefixes(a.trustedCIDRs); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Middleware answers 403 to requests from IPs outside the allowlist and passes the others to next.
+func (a *IPAllowlist) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.Check(r); err != nil {
+			if a.OnReject != nil {
+				a.OnReject(r, err)
+			}
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Check returns an *IPRejectedError unless the client IP of r is allowed.
+func (a *IPAllowlist) Check(r *http.Request) error {
+	ip, forwardedFor, err := a.ClientIP(r)
+	if err != nil {
+		return &IPRejectedError{RemoteAddr: r.RemoteAddr, ForwardedFor: forwardedFor, Err: err}
+	}
+	if !contains(a.allowed, ip) {
+		return &IPRejectedError{IP: ip.String(), RemoteAddr: r.RemoteAddr, ForwardedFor: forwardedFor}
+	}
+	return nil
+}
+
+// ClientIP returns the IP of the client that sent r, and the X-Forwarded-For header when it
+// was used. The header is read right to left, skipping trusted proxies, and only when the
+// peer connection comes from a trusted proxy. It returns ErrNoClientIP when every hop is a
+// trusted proxy, including when the header is missing.
+func (a *IPAllowlist) ClientIP(r *http.Request) (netip.Addr, string, error) {
+	remote, err := parseAddr(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, "", err
+	}
+	if !contains(a.trustedProxies, remote) {
+		return remote, "", nil
+	}
+
+	forwardedFor := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip, err := parseAddr(hop)
+		if err != nil {
+			return netip.Addr{}, forwardedFor, err
+		}
+		if !contains(a.trustedProxies, ip) {
+			return ip, forwardedFor, nil
+		}
+	}
+	return netip.Addr{}, forwardedFor, ErrNoClientIP
+}
+
+// parseAddr parses an IP with or without a port, mapping IPv4-in-IPv6 addresses to IPv4.
+func parseAddr(s string) (netip.Addr, error) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid address %q", s)
+	}
+	return ip.Unmap().WithZone(""), nil
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, cidr)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, cidr)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// contains reports whether ip, with IPv4-in-IPv6 addresses mapped to IPv4, is in prefixes.
+func contains(prefixes []netip.Prefix, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package postback
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIPAllowlist(t *testing.T) {
+	allowlist, err := NewIPAllowlist([]string{"203.0.113.0/24", "2001:db8::/32", "198.51.100.7", "::ffff:192.0.2.128/121"}, WithTrustedProxies("10.0.0.0/8", "::ffff:172.16.0.1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expectedCode int
+		expectedIP   string
+		expectedErr  error
+	}{
+		{name: "allowed range", remoteAddr: "203.0.113.10:5000", expectedCode: http.StatusOK},
+		{name: "allowed single IP", remoteAddr: "198.51.100.7:5000", expectedCode: http.StatusOK},
+		{name: "allowed IPv6", remoteAddr: "[2001:db8::1]:5000", expectedCode: http.StatusOK},
+		{name: "mapped IPv4", remoteAddr: "[::ffff:203.0.113.10]:5000", expectedCode: http.StatusOK},
+		{name: "not allowed", remoteAddr: "192.0.2.1:5000", expectedCode: http.StatusForbidden, expectedIP: "192.0.2.1"},
+		{name: "spoofed header from untrusted peer", remoteAddr: "192.0.2.1:5000", forwardedFor: []string{"203.0.113.10"}, expectedCode: http.StatusForbidden, expectedIP: "192.0.2.1"},
+		{name: "forwarded by trusted proxy", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"203.0.113.10"}, expectedCode: http.StatusOK},
+		{name: "forwarded through proxy chain", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"203.0.113.10, 10.0.0.3"}, expectedCode: http.StatusOK},
+		{name: "spoofed leftmost hop", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"203.0.113.10, 192.0.2.1"}, expectedCode: http.StatusForbidden, expectedIP: "192.0.2.1"},
+		{name: "multiple headers", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"192.0.2.1", "203.0.113.10"}, expectedCode: http.StatusOK},
+		{name: "mapped allowed range", remoteAddr: "192.0.2.130:5000", expectedCode: http.StatusOK},
+		{name: "mapped trusted proxy", remoteAddr: "172.16.0.1:5000", forwardedFor: []string{"203.0.113.10"}, expectedCode: http.StatusOK},
+		{name: "trusted proxy without header", remoteAddr: "10.0.0.2:5000", expectedCode: http.StatusForbidden, expectedErr: ErrNoClientIP},
+		{name: "only trusted hops", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"10.0.0.3, 10.0.0.4"}, expectedCode: http.StatusForbidden, expectedErr: ErrNoClientIP},
+		{name: "malformed header", remoteAddr: "10.0.0.2:5000", forwardedFor: []string{"not-an-ip"}, expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			allowlist.OnReject = func(r *http.Request, err error) { reported = err }
+			called := false
+			h := allowlist.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			req := httptest.NewRequest("GET", "/postback", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if called != (tt.expectedCode == http.StatusOK) {
+				t.Errorf("expected next handler called %v, got %v", tt.expectedCode == http.StatusOK, called)
+			}
+			if tt.expectedCode == http.StatusOK {
+				if reported != nil {
+					t.Errorf("expected no rejection, got %v", reported)
+				}
+				return
+			}
+
+			var rejected *IPRejectedError
+			if !errors.As(reported, &rejected) || !errors.Is(reported, ErrIPNotAllowed) {
+				t.Fatalf("expected *IPRejectedError, got %v", reported)
+			}
+			if tt.expectedErr != nil && !errors.Is(reported, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, reported)
+			}
+			for _, err := range rejected.Unwrap() {
+				if err == nil {
+					t.Errorf("expected no nil wrapped error, got %v", rejected.Unwrap())
+				}
+			}
+			if rejected.IP != tt.expectedIP {
+				t.Errorf("expected rejected IP %q, got %q", tt.expectedIP, rejected.IP)
+			}
+			if rejected.RemoteAddr != tt.remoteAddr {
+				t.Errorf("expected remote address %q, got %q", tt.remoteAddr, rejected.RemoteAddr)
+			}
+		})
+	}
+}
+
+func TestNewIPAllowlist_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		opts    []IPAllowlistOptions
+	}{
+		{name: "invalid range", allowed: []string{"203.0.113.0/33"}},
+		{name: "invalid IP", allowed: []string{"tyrads.com"}},
+		{name: "invalid trusted proxy", allowed: []string{"203.0.113.0/24"}, opts: []IPAllowlistOptions{WithTrustedProxies("10.0.0.0/x")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewIPAllowlist(tt.allowed, tt.opts...); !errors.Is(err, ErrInvalidCIDR) {
+				t.Errorf("expected ErrInvalidCIDR, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIPAllowlist_Empty(t *testing.T) {
+	allowlist, err := NewIPAllowlist(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowlist.OnReject != nil {
+		t.Error("expected no default OnReject")
+	}
+	req := httptest.NewRequest("GET", "/postback", nil)
+	req.RemoteAddr = "203.0.113.10:5000"
+	if err := allowlist.Check(req); !errors.Is(err, ErrIPNotAllowed) {
+		t.Errorf("expected ErrIPNotAllowed, got %v", err)
+	}
+}
+
+func TestLogRejections(t *testing.T) {
+	var buf bytes.Buffer
+	allowlist, err := NewIPAllowlist([]string{"198.51.100.0/24"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	allowlist.OnReject = LogRejections(log.New(&buf, "", 0))
+
+	req := httptest.NewRequest("GET", "/postback", nil)
+	req.RemoteAddr = "203.0.113.10:5000"
+	rec := httptest.NewRecorder()
+	allowlist.Middleware(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "tyrads: rejected postback GET /postback: ") || !strings.Contains(got, "203.0.113.10") {
+		t.Errorf("expected the rejection to be logged, got %q", got)
+	}
+}
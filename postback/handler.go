@@ -0,0 +1,159 @@
+package postback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/userid"
+)
+
+// RewardFunc credits the user of a verified reward event. Returning an error answers
+// the callback with 500 so the sender retries it.
+type RewardFunc func(ctx context.Context, event Event) error
+
+// ReversalFunc claws back the reward of a verified reversal event. Returning an error answers
+// the callback with 500 so the sender retries it. A reversal may arrive before the reward it
+// reverses; ledger.ReversalFunc records it and applies it when the reward arrives.
+type ReversalFunc func(ctx context.Context, event ReversalEvent) error
+
+// ErrReversalNotHandled is reported when a reversal callback reaches a Handler without OnReversal.
+var ErrReversalNotHandled = errors.New("postback reversal received without a reversal handler")
+
+// Handler is an http.Handler verifying reward callbacks before passing them to a RewardFunc.
+type Handler struct {
+	secret   string
+	onReward RewardFunc
+
+	// UserIDMapper, when set, resolves Event.UserID from the pseudonymous publisher user ID.
+	// Use the mapper given to TyrAdsSdk.UserIDMapper.
+	UserIDMapper userid.Mapper
+	// OnReversal, when set, handles reversal and chargeback callbacks.
+	OnReversal ReversalFunc
+	// ParseSettings bounds the accepted callback timestamps. Defaults to DefaultParseSettings.
+	ParseSettings ParseSettings
+	// OnError is called with every rejected or failed callback.
+	OnError func(r *http.Request, err error)
+}
+
+type HandlerOptions func(*Handler)
+
+// NewHandler creates a Handler verifying callbacks with the API secret.
+func NewHandler(secret string, onReward RewardFunc, opts ...HandlerOptions) *Handler {
+	h := &Handler{
+		secret:        secret,
+		onReward:      onReward,
+		ParseSettings: DefaultParseSettings(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP answers 200 once the reward or reversal has been handled, 401 for an invalid
+// signature, 400 for a malformed or stale callback or an unknown user, 501 for a reversal without
+// OnReversal, and 500 if the user ID lookup or the hook failed.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.reject(w, r, fmt.Errorf("%w: %w", ErrInvalidParam, err))
+		return
+	}
+	if IsReversal(r.Form) {
+		h.serveReversal(w, r)
+		return
+	}
+
+	event, err := Parse(r.Form, h.secret, h.parseOptions)
+	if err != nil {
+		h.reject(w, r, err)
+		return
+	}
+	userID, ok := h.resolveUserID(w, r, event.PublisherUserID)
+	if !ok {
+		return
+	}
+	event.UserID = userID
+
+	if err := h.onReward(r.Context(), *event); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+func (h *Handler) serveReversal(w http.ResponseWriter, r *http.Request) {
+	event, err := ParseReversal(r.Form, h.secret, h.parseOptions)
+	if err != nil {
+		h.reject(w, r, err)
+		return
+	}
+	if h.OnReversal == nil {
+		h.reportError(r, ErrReversalNotHandled)
+		http.Error(w, "reversals not supported", http.StatusNotImplemented)
+		return
+	}
+	userID, ok := h.resolveUserID(w, r, event.PublisherUserID)
+	if !ok {
+		return
+	}
+	event.UserID = userID
+
+	if err := h.OnReversal(r.Context(), *event); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+func (h *Handler) parseOptions(s *ParseSettings) {
+	*s = h.ParseSettings
+}
+
+// resolveUserID maps the publisher user ID with UserIDMapper. It answers the callback and
+// returns false when the lookup fails.
+func (h *Handler) resolveUserID(w http.ResponseWriter, r *http.Request, publisherUserID string) (string, bool) {
+	if h.UserIDMapper == nil {
+		return publisherUserID, true
+	}
+	userID, err := h.UserIDMapper.ToInternal(r.Context(), publisherUserID)
+	if err != nil {
+		if errors.Is(err, userid.ErrNotFound) {
+			h.reportError(r, err)
+			http.Error(w, "unknown user", http.StatusBadRequest)
+			return "", false
+		}
+		h.fail(w, r, err)
+		return "", false
+	}
+	return userID, true
+}
+
+// reject answers a callback that failed verification or parsing.
+func (h *Handler) reject(w http.ResponseWriter, r *http.Request, err error) {
+	h.reportError(r, err)
+	if errors.Is(err, ErrInvalidSignature) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	http.Error(w, "invalid postback", http.StatusBadRequest)
+}
+
+// fail answers a callback that could not be processed, so the sender retries it.
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	h.reportError(r, err)
+	http.Error(w, "failed to process postback", http.StatusInternalServerError)
+}
+
+func (h *Handler) reportError(r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+}
@@ -0,0 +1,193 @@
+package postback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/userid"
+)
+
+func TestHandler(t *testing.T) {
+	valid := testEvent().SignedValues("test-secret")
+	tampered := testEvent().SignedValues("test-secret")
+	tampered.Set(ParamAmount, "1000")
+	malformed := testEvent().Values()
+	malformed.Set(ParamAmount, "ten")
+	malformed.Set(ParamSignature, Sign(malformed, "test-secret"))
+	staleEvent := testEvent()
+	staleEvent.Timestamp = staleEvent.Timestamp.Add(-DefaultMaxAge - time.Hour)
+	stale := staleEvent.SignedValues("test-secret")
+	negativeEvent := testEvent()
+	negativeEvent.Amount = -5
+	negative := negativeEvent.SignedValues("test-secret")
+
+	tests := []struct {
+		name         string
+		query        string
+		rewardErr    error
+		expectedCode int
+		expectReward bool
+	}{
+		{name: "valid", query: valid.Encode(), expectedCode: http.StatusOK, expectReward: true},
+		{name: "tampered", query: tampered.Encode(), expectedCode: http.StatusUnauthorized},
+		{name: "malformed", query: malformed.Encode(), expectedCode: http.StatusBadRequest},
+		{name: "stale", query: stale.Encode(), expectedCode: http.StatusBadRequest},
+		{name: "negative amount", query: negative.Encode(), expectedCode: http.StatusBadRequest},
+		{name: "reward failure", query: valid.Encode(), rewardErr: errors.New("db down"), expectedCode: http.StatusInternalServerError, expectReward: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rewarded *Event
+			var reported error
+			h := NewHandler("test-secret", func(ctx context.Context, event Event) error {
+				rewarded = &event
+				return tt.rewardErr
+			}, func(h *Handler) {
+				h.OnError = func(r *http.Request, err error) { reported = err }
+			})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/postback?"+tt.query, nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if (rewarded != nil) != tt.expectReward {
+				t.Errorf("expected reward called %v, got %v", tt.expectReward, rewarded != nil)
+			}
+			if tt.expectedCode != http.StatusOK && reported == nil {
+				t.Error("expected OnError to be called")
+			}
+		})
+	}
+}
+
+func TestHandler_UserIDMapper(t *testing.T) {
+	ctx := context.Background()
+	mapper, err := userid.NewHMACMapper([]byte("local-key-0123456789"), userid.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	externalID, _ := mapper.ToExternal(ctx, "internal-42")
+
+	tests := []struct {
+		name           string
+		mapper         userid.Mapper
+		publisherID    string
+		expectedCode   int
+		expectedUserID string
+	}{
+		{name: "known user", mapper: mapper, publisherID: externalID, expectedCode: http.StatusOK, expectedUserID: "internal-42"},
+		{name: "unknown user", mapper: mapper, publisherID: "unknown", expectedCode: http.StatusBadRequest},
+		{
+			name:         "lookup failure",
+			mapper:       failingMapper(t),
+			publisherID:  externalID,
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testEvent()
+			event.PublisherUserID = tt.publisherID
+
+			var rewarded *Event
+			h := NewHandler("test-secret", func(ctx context.Context, event Event) error {
+				rewarded = &event
+				return nil
+			}, func(h *Handler) { h.UserIDMapper = tt.mapper })
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/postback?"+event.SignedValues("test-secret").Encode(), nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if tt.expectedUserID == "" {
+				if rewarded != nil {
+					t.Error("expected reward not to be called")
+				}
+				return
+			}
+			if rewarded.UserID != tt.expectedUserID || rewarded.PublisherUserID != tt.publisherID {
+				t.Errorf("expected user %s for %s, got %+v", tt.expectedUserID, tt.publisherID, rewarded)
+			}
+		})
+	}
+}
+
+func TestHandler_Reversal(t *testing.T) {
+	valid := testReversalEvent().SignedValues("test-secret")
+	tampered := testReversalEvent().SignedValues("test-secret")
+	tampered.Set(ParamTransactionID, "txn-2")
+
+	tests := []struct {
+		name           string
+		query          string
+		noHook         bool
+		reversalErr    error
+		expectedCode   int
+		expectReversal bool
+	}{
+		{name: "valid", query: valid.Encode(), expectedCode: http.StatusOK, expectReversal: true},
+		{name: "tampered", query: tampered.Encode(), expectedCode: http.StatusUnauthorized},
+		{name: "no hook", query: valid.Encode(), noHook: true, expectedCode: http.StatusNotImplemented},
+		{name: "reversal failure", query: valid.Encode(), reversalErr: errors.New("credit not found"), expectedCode: http.StatusInternalServerError, expectReversal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reversed *ReversalEvent
+			var reported error
+			rewarded := false
+			h := NewHandler("test-secret", func(ctx context.Context, event Event) error {
+				rewarded = true
+				return nil
+			}, func(h *Handler) {
+				h.OnError = func(r *http.Request, err error) { reported = err }
+				if !tt.noHook {
+					h.OnReversal = func(ctx context.Context, event ReversalEvent) error {
+						reversed = &event
+						return tt.reversalErr
+					}
+				}
+			})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/postback?"+tt.query, nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if rewarded {
+				t.Error("expected reward not to be called for a reversal")
+			}
+			if (reversed != nil) != tt.expectReversal {
+				t.Errorf("expected reversal called %v, got %v", tt.expectReversal, reversed != nil)
+			}
+			if reversed != nil && *reversed != testReversalEvent() {
+				t.Errorf("expected %+v, got %+v", testReversalEvent(), *reversed)
+			}
+			if tt.noHook && !errors.Is(reported, ErrReversalNotHandled) {
+				t.Errorf("expected ErrReversalNotHandled, got %v", reported)
+			}
+		})
+	}
+}
+
+// failingMapper returns a userid.Mapper whose lookups fail in both directions.
+func failingMapper(t *testing.T) userid.Mapper {
+	t.Helper()
+	fail := func(ctx context.Context, id string) (string, error) { return "", errors.New("store down") }
+	mapper, err := userid.NewMapperFuncs(fail, fail)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return mapper
+}
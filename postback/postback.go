@@ -0,0 +1,207 @@
+// Package postback verifies the reward and reversal callbacks TyrAds sends to the publisher
+// server, and credits them through a RewardFunc.
+//
+// Provisional: the callback format is not part of the documented TyrAds API. The parameter
+// names, the HMAC-SHA256 signature over the sorted query string and the "type=reversal"
+// marker are assumptions; callbacks in another format are rejected. Confirm the format with
+// TyrAds before relying on this package.
+package postback
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/internal/signing"
+)
+
+// Query parameters of a reward callback.
+const (
+	ParamTransactionID   = "transactionId"
+	ParamPublisherUserID = "publisherUserId"
+	ParamAmount          = "amount"
+	ParamOfferID         = "offerId"
+	ParamTimestamp       = "ts"
+	ParamSignature       = "signature"
+)
+
+var (
+	ErrMissingParam     = errors.New("missing postback parameter")
+	ErrInvalidParam     = errors.New("invalid postback parameter")
+	ErrInvalidSignature = errors.New("invalid postback signature")
+	ErrStale            = errors.New("postback timestamp outside the accepted window")
+)
+
+// Default freshness window of callback timestamps. The signature does not prevent a
+// captured callback from being replayed, so old callbacks are rejected.
+const (
+	DefaultMaxAge       = 24 * time.Hour
+	DefaultMaxClockSkew = 5 * time.Minute
+)
+
+// ParseSettings bounds the accepted callback timestamps.
+type ParseSettings struct {
+	// MaxAge rejects callbacks timestamped longer ago than this. Zero disables the check.
+	MaxAge time.Duration
+	// MaxClockSkew rejects callbacks timestamped further in the future than this.
+	MaxClockSkew time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+type ParseOptions func(*ParseSettings)
+
+// DefaultParseSettings returns the settings used when no option is given.
+func DefaultParseSettings() ParseSettings {
+	return ParseSettings{
+		MaxAge:       DefaultMaxAge,
+		MaxClockSkew: DefaultMaxClockSkew,
+		Now:          time.Now,
+	}
+}
+
+func newParseSettings(opts []ParseOptions) ParseSettings {
+	settings := DefaultParseSettings()
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+	return settings
+}
+
+// checkTimestamp returns ErrStale if ts is outside the accepted window.
+func (s ParseSettings) checkTimestamp(ts time.Time) error {
+	now := s.Now()
+	if ts.After(now.Add(s.MaxClockSkew)) {
+		return fmt.Errorf("%w: %s is %s in the future", ErrStale, ParamTimestamp, ts.Sub(now))
+	}
+	if s.MaxAge > 0 && now.Sub(ts) > s.MaxAge {
+		return fmt.Errorf("%w: %s is %s old", ErrStale, ParamTimestamp, now.Sub(ts).Truncate(time.Second))
+	}
+	return nil
+}
+
+// parseTimestamp decodes the timestamp parameter and checks it against the settings.
+func parseTimestamp(values url.Values, opts []ParseOptions) (time.Time, error) {
+	sec, err := strconv.ParseInt(values.Get(ParamTimestamp), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidParam, ParamTimestamp)
+	}
+	ts := time.Unix(sec, 0)
+	if err := newParseSettings(opts).checkTimestamp(ts); err != nil {
+		return time.Time{}, err
+	}
+	return ts, nil
+}
+
+// Event is a reward callback crediting a user.
+type Event struct {
+	TransactionID   string
+	PublisherUserID string
+	// UserID is the internal user ID resolved by Handler.UserIDMapper. It equals
+	// PublisherUserID when no mapper is set.
+	UserID    string
+	Amount    float64
+	OfferID   string
+	Timestamp time.Time
+}
+
+// Values returns the unsigned query parameters of the event.
+func (e Event) Values() url.Values {
+	values := url.Values{}
+	values.Set(ParamTransactionID, e.TransactionID)
+	values.Set(ParamPublisherUserID, e.PublisherUserID)
+	values.Set(ParamAmount, strconv.FormatFloat(e.Amount, 'f', -1, 64))
+	if e.OfferID != "" {
+		values.Set(ParamOfferID, e.OfferID)
+	}
+	values.Set(ParamTimestamp, strconv.FormatInt(e.Timestamp.Unix(), 10))
+	return values
+}
+
+// SignedValues returns the query parameters of the event including its signature.
+func (e Event) SignedValues(secret string) url.Values {
+	values := e.Values()
+	values.Set(ParamSignature, Sign(values, secret))
+	return values
+}
+
+// Sign returns the hex encoded HMAC-SHA256, keyed with the API secret, of the sorted and
+// URL-encoded query string without the signature parameter.
+func Sign(values url.Values, secret string) string {
+	return signing.Sign(values, ParamSignature, secret)
+}
+
+// Verify checks the signature of values against secret.
+func Verify(values url.Values, secret string) error {
+	signature := values.Get(ParamSignature)
+	if signature == "" {
+		return fmt.Errorf("%w: missing %s", ErrInvalidSignature, ParamSignature)
+	}
+	if !signing.Valid(values, ParamSignature, secret) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Parse verifies the signature of values and decodes the reward event. Reversal callbacks
+// are rejected with ErrInvalidParam; decode them with ParseReversal. Callbacks timestamped
+// outside the window of ParseSettings, DefaultMaxAge by default, are rejected with ErrStale.
+func Parse(values url.Values, secret string, opts ...ParseOptions) (*Event, error) {
+	if err := Verify(values, secret); err != nil {
+		return nil, err
+	}
+	if t := values.Get(ParamType); t != "" && t != TypeReward {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParam, ParamType)
+	}
+	for _, param := range []string{ParamTransactionID, ParamPublisherUserID, ParamAmount, ParamTimestamp} {
+		if values.Get(param) == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingParam, param)
+		}
+	}
+
+	amount, err := parseAmount(values)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := parseTimestamp(values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		TransactionID:   values.Get(ParamTransactionID),
+		PublisherUserID: values.Get(ParamPublisherUserID),
+		UserID:          values.Get(ParamPublisherUserID),
+		Amount:          amount,
+		OfferID:         values.Get(ParamOfferID),
+		Timestamp:       ts,
+	}, nil
+}
+
+// parseAmount returns the amount of values, rejecting NaN, infinite and negative amounts
+// with ErrInvalidParam. A missing amount is 0.
+func parseAmount(values url.Values) (float64, error) {
+	if values.Get(ParamAmount) == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(values.Get(ParamAmount), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidParam, ParamAmount)
+	}
+	return amount, nil
+}
+
+// ParseRequest parses a reward callback sent either as GET query parameters or as a POST form.
+func ParseRequest(r *http.Request, secret string, opts ...ParseOptions) (*Event, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParam, err)
+	}
+	return Parse(r.Form, secret, opts...)
+}
@@ -0,0 +1,136 @@
+package postback
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTimestamp is a recent callback timestamp, truncated to the second like the ts parameter.
+var testTimestamp = time.Unix(time.Now().Unix(), 0)
+
+func testEvent() Event {
+	return Event{
+		TransactionID:   "txn-1",
+		PublisherUserID: "user123",
+		UserID:          "user123",
+		Amount:          12.5,
+		OfferID:         "offer-9",
+		Timestamp:       testTimestamp,
+	}
+}
+
+func TestParse(t *testing.T) {
+	values := testEvent().SignedValues("test-secret")
+
+	event, err := Parse(values, "test-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *event != testEvent() {
+		t.Errorf("expected %+v, got %+v", testEvent(), *event)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tamper := func(key, value string) url.Values {
+		values := testEvent().SignedValues("test-secret")
+		values.Set(key, value)
+		return values
+	}
+	resign := func(key, value string) url.Values {
+		values := testEvent().Values()
+		values.Set(key, value)
+		values.Set(ParamSignature, Sign(values, "test-secret"))
+		return values
+	}
+	unsigned := testEvent().Values()
+
+	tests := []struct {
+		name        string
+		values      url.Values
+		expectedErr error
+	}{
+		{name: "tampered amount", values: tamper(ParamAmount, "1000"), expectedErr: ErrInvalidSignature},
+		{name: "missing signature", values: unsigned, expectedErr: ErrInvalidSignature},
+		{name: "missing transaction", values: resign(ParamTransactionID, ""), expectedErr: ErrMissingParam},
+		{name: "invalid amount", values: resign(ParamAmount, "ten"), expectedErr: ErrInvalidParam},
+		{name: "NaN amount", values: resign(ParamAmount, "NaN"), expectedErr: ErrInvalidParam},
+		{name: "infinite amount", values: resign(ParamAmount, "+Inf"), expectedErr: ErrInvalidParam},
+		{name: "negative amount", values: resign(ParamAmount, "-5"), expectedErr: ErrInvalidParam},
+		{name: "invalid timestamp", values: resign(ParamTimestamp, "yesterday"), expectedErr: ErrInvalidParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.values, "test-secret")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	if _, err := Parse(testEvent().SignedValues("other-secret"), "test-secret"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+}
+
+func TestParse_Freshness(t *testing.T) {
+	now := testTimestamp
+	withNow := func(s *ParseSettings) { s.Now = func() time.Time { return now } }
+	at := func(ts time.Time) url.Values {
+		event := testEvent()
+		event.Timestamp = ts
+		return event.SignedValues("test-secret")
+	}
+
+	tests := []struct {
+		name        string
+		values      url.Values
+		opts        []ParseOptions
+		expectedErr error
+	}{
+		{name: "recent", values: at(now.Add(-time.Hour))},
+		{name: "too old", values: at(now.Add(-DefaultMaxAge - time.Second)), expectedErr: ErrStale},
+		{name: "within clock skew", values: at(now.Add(DefaultMaxClockSkew))},
+		{name: "too far in the future", values: at(now.Add(DefaultMaxClockSkew + time.Second)), expectedErr: ErrStale},
+		{
+			name:        "custom max age",
+			values:      at(now.Add(-2 * time.Minute)),
+			opts:        []ParseOptions{func(s *ParseSettings) { s.MaxAge = time.Minute }},
+			expectedErr: ErrStale,
+		},
+		{
+			name:   "max age disabled",
+			values: at(now.Add(-30 * 24 * time.Hour)),
+			opts:   []ParseOptions{func(s *ParseSettings) { s.MaxAge = 0 }},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.values, "test-secret", append([]ParseOptions{withNow}, tt.opts...)...)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	values := testEvent().SignedValues("test-secret")
+
+	get := httptest.NewRequest("GET", "/postback?"+values.Encode(), nil)
+	if _, err := ParseRequest(get, "test-secret"); err != nil {
+		t.Errorf("unexpected error for GET: %v", err)
+	}
+
+	post := httptest.NewRequest("POST", "/postback", strings.NewReader(values.Encode()))
+	post.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := ParseRequest(post, "test-secret"); err != nil {
+		t.Errorf("unexpected error for POST: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package postback
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters of a reversal callback, in addition to the reward parameters.
+const (
+	ParamType   = "type"
+	ParamReason = "reason"
+)
+
+// Values of ParamType. Callbacks without a type are rewards.
+const (
+	TypeReward   = "reward"
+	TypeReversal = "reversal"
+)
+
+// ReversalEvent is a callback reversing a previously rewarded transaction, for a
+// chargeback or a fraudulent conversion.
+type ReversalEvent struct {
+	// TransactionID is the ID of the reversed reward.
+	TransactionID   string
+	PublisherUserID string
+	// UserID is the internal user ID resolved by Handler.UserIDMapper. It equals
+	// PublisherUserID when no mapper is set.
+	UserID string
+	// Amount is the reversed amount reported by the callback, or 0 when it is not sent.
+	Amount    float64
+	OfferID   string
+	Reason    string
+	Timestamp time.Time
+}
+
+// Values returns the unsigned query parameters of the event.
+func (e ReversalEvent) Values() url.Values {
+	values := url.Values{}
+	values.Set(ParamType, TypeReversal)
+	values.Set(ParamTransactionID, e.TransactionID)
+	values.Set(ParamPublisherUserID, e.PublisherUserID)
+	if e.Amount != 0 {
+		values.Set(ParamAmount, strconv.FormatFloat(e.Amount, 'f', -1, 64))
+	}
+	if e.OfferID != "" {
+		values.Set(ParamOfferID, e.OfferID)
+	}
+	if e.Reason != "" {
+		values.Set(ParamReason, e.Reason)
+	}
+	values.Set(ParamTimestamp, strconv.FormatInt(e.Timestamp.Unix(), 10))
+	return values
+}
+
+// SignedValues returns the query parameters of the event including its signature.
+func (e ReversalEvent) SignedValues(secret string) url.Values {
+	values := e.Values()
+	values.Set(ParamSignature, Sign(values, secret))
+	return values
+}
+
+// IsReversal reports whether values are a reversal callback.
+func IsReversal(values url.Values) bool {
+	return values.Get(ParamType) == TypeReversal
+}
+
+// ParseReversal verifies the signature of values and decodes the reversal event. Its
+// timestamp is checked like in Parse.
+func ParseReversal(values url.Values, secret string, opts ...ParseOptions) (*ReversalEvent, error) {
+	if err := Verify(values, secret); err != nil {
+		return nil, err
+	}
+	if !IsReversal(values) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParam, ParamType)
+	}
+	for _, param := range []string{ParamTransactionID, ParamPublisherUserID, ParamTimestamp} {
+		if values.Get(param) == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingParam, param)
+		}
+	}
+
+	amount, err := parseAmount(values)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := parseTimestamp(values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReversalEvent{
+		TransactionID:   values.Get(ParamTransactionID),
+		PublisherUserID: values.Get(ParamPublisherUserID),
+		UserID:          values.Get(ParamPublisherUserID),
+		Amount:          amount,
+		OfferID:         values.Get(ParamOfferID),
+		Reason:          values.Get(ParamReason),
+		Timestamp:       ts,
+	}, nil
+}
+
+// ParseReversalRequest parses a reversal callback sent either as GET query parameters or as a POST form.
+func ParseReversalRequest(r *http.Request, secret string, opts ...ParseOptions) (*ReversalEvent, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParam, err)
+	}
+	return ParseReversal(r.Form, secret, opts...)
+}
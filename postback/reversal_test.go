@@ -0,0 +1,104 @@
+package postback
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReversalEvent() ReversalEvent {
+	return ReversalEvent{
+		TransactionID:   "txn-1",
+		PublisherUserID: "user123",
+		UserID:          "user123",
+		Amount:          12.5,
+		OfferID:         "offer-9",
+		Reason:          "chargeback",
+		Timestamp:       testTimestamp.Add(100 * time.Second),
+	}
+}
+
+func TestParseReversal(t *testing.T) {
+	event, err := ParseReversal(testReversalEvent().SignedValues("test-secret"), "test-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *event != testReversalEvent() {
+		t.Errorf("expected %+v, got %+v", testReversalEvent(), *event)
+	}
+
+	withoutAmount := testReversalEvent()
+	withoutAmount.Amount = 0
+	event, err = ParseReversal(withoutAmount.SignedValues("test-secret"), "test-secret")
+	if err != nil {
+		t.Fatalf("unexpected error without amount: %v", err)
+	}
+	if event.Amount != 0 {
+		t.Errorf("expected amount 0, got %v", event.Amount)
+	}
+}
+
+func TestParseReversal_Errors(t *testing.T) {
+	tamper := func(key, value string) url.Values {
+		values := testReversalEvent().SignedValues("test-secret")
+		values.Set(key, value)
+		return values
+	}
+	resign := func(key, value string) url.Values {
+		values := testReversalEvent().Values()
+		values.Set(key, value)
+		values.Set(ParamSignature, Sign(values, "test-secret"))
+		return values
+	}
+
+	tests := []struct {
+		name        string
+		values      url.Values
+		expectedErr error
+	}{
+		{name: "tampered transaction", values: tamper(ParamTransactionID, "txn-2"), expectedErr: ErrInvalidSignature},
+		{name: "stripped type", values: tamper(ParamType, ""), expectedErr: ErrInvalidSignature},
+		{name: "reward callback", values: testEvent().SignedValues("test-secret"), expectedErr: ErrInvalidParam},
+		{name: "missing transaction", values: resign(ParamTransactionID, ""), expectedErr: ErrMissingParam},
+		{name: "missing user", values: resign(ParamPublisherUserID, ""), expectedErr: ErrMissingParam},
+		{name: "invalid amount", values: resign(ParamAmount, "ten"), expectedErr: ErrInvalidParam},
+		{name: "NaN amount", values: resign(ParamAmount, "nan"), expectedErr: ErrInvalidParam},
+		{name: "infinite amount", values: resign(ParamAmount, "-Inf"), expectedErr: ErrInvalidParam},
+		{name: "negative amount", values: resign(ParamAmount, "-5"), expectedErr: ErrInvalidParam},
+		{name: "invalid timestamp", values: resign(ParamTimestamp, "yesterday"), expectedErr: ErrInvalidParam},
+		{name: "stale", values: resign(ParamTimestamp, "1700000000"), expectedErr: ErrStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReversal(tt.values, "test-secret")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParse_RejectsReversal(t *testing.T) {
+	if _, err := Parse(testReversalEvent().SignedValues("test-secret"), "test-secret"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam for a reversal callback, got %v", err)
+	}
+}
+
+func TestParseReversalRequest(t *testing.T) {
+	values := testReversalEvent().SignedValues("test-secret")
+
+	get := httptest.NewRequest("GET", "/postback?"+values.Encode(), nil)
+	if _, err := ParseReversalRequest(get, "test-secret"); err != nil {
+		t.Errorf("unexpected error for GET: %v", err)
+	}
+
+	post := httptest.NewRequest("POST", "/postback", strings.NewReader(values.Encode()))
+	post.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := ParseReversalRequest(post, "test-secret"); err != nil {
+		t.Errorf("unexpected error for POST: %v", err)
+	}
+}
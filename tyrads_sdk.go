@@ -1,22 +1,69 @@
 package tyrads
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/launch"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/userid"
 )
 
 type AuthenticationRequest = contract.AuthenticationRequest
 type AuthenticationSign = contract.AuthenticationSign
 
+// Errors returned by Authenticate. They wrap the underlying cause, so callers can
+// classify a failure with errors.Is and still inspect the cause with errors.As.
+var (
+	ErrValidation      = errors.New("validation error")
+	ErrRequest         = errors.New("request error")
+	ErrInvalidResponse = errors.New("invalid response")
+	ErrUserIDMapping   = errors.New("user ID mapping error")
+)
+
+// TyrAdsSdk calls the TyrAds API. Its exported fields adjust the user data that is sent;
+// set them before the SDK is used concurrently.
 type TyrAdsSdk struct {
 	config     *config.Config
 	httpClient *client.HttpClient
+
+	// PrivacyMode controls how email and phone number are sent, unless a request overrides
+	// it. Defaults to enum.PrivacyModeRaw.
+	PrivacyMode enum.PrivacyMode
+	// OnDataSent, when set, is called with the user data of every request that left the
+	// process, for auditing. Requests blocked before being sent, for example by the rate
+	// limiter or the circuit breaker, are not reported.
+	OnDataSent func(event DataSentEvent)
+	// ConsentPolicy adjusts each request to its consent fields before it is sent. Defaults
+	// to contract.DefaultConsentPolicy; set it to nil to send the profile regardless.
+	ConsentPolicy contract.ConsentPolicy
+	// UserIDMapper, when set, replaces the publisher user ID by a pseudonymous ID before it is sent.
+	UserIDMapper userid.Mapper
+	// OnCircuitStateChange, when set, is called after every transition of the circuit breaker
+	// enabled by Config.CircuitBreaker. Transitions are passed one at a time, in order.
+	OnCircuitStateChange func(from, to enum.CircuitState)
+}
+
+// DataSentEvent describes the user data sent to TyrAds for one request.
+type DataSentEvent struct {
+	// Destination is the API path, such as "/auth", or "launch_url" for signed launch URLs.
+	Destination     string
+	PublisherUserID string
+	PrivacyMode     enum.PrivacyMode
+	// Fields lists the payload keys sent, sorted.
+	Fields []string
+	// Hashed and Omitted list the personal data keys that were hashed or dropped, sorted.
+	// Omitted includes the keys dropped by the privacy mode, the consent policy and the
+	// age policy.
+	Hashed  []string
+	Omitted []string
 }
 
 // NewTyrAdsSdk creates and returns a new instance of TyrAdsSdk with the specified configuration.
@@ -26,10 +73,11 @@ type TyrAdsSdk struct {
 //   - apiKey: The API key for authentication. If empty, it will be retrieved from the TYRADS_API_KEY environment variable.
 //   - apiSecret: The API secret for authentication. If empty, it will be retrieved from the TYRADS_API_SECRET environment variable.
 //   - lang: The language code for SDK responses. Defaults to "en" if not specified or empty.
+//   - opts: Optional functions adjusting the configuration, applied after the parameters above.
 //
 // Returns:
 //   - *TyrAdsSdk: A pointer to the newly created TyrAdsSdk instance configured with the provided parameters.
-func NewTyrAdsSdk(apiKey, apiSecret, lang string) *TyrAdsSdk {
+func NewTyrAdsSdk(apiKey, apiSecret, lang string, opts ...config.ConfigOptions) *TyrAdsSdk {
 	if apiKey == "" {
 		apiKey = os.Getenv(string(enum.TYRADS_API_KEY))
 	}
@@ -39,13 +87,22 @@ func NewTyrAdsSdk(apiKey, apiSecret, lang string) *TyrAdsSdk {
 	if lang == "" {
 		lang = "en"
 	}
-	cfg := config.NewConfig(apiKey, apiSecret, func(c *config.Config) {
+	opts = append([]config.ConfigOptions{func(c *config.Config) {
 		c.Language = lang
-	})
-	return &TyrAdsSdk{
-		config:     cfg,
-		httpClient: client.NewHttpClient(cfg),
+	}}, opts...)
+	cfg := config.NewConfig(apiKey, apiSecret, opts...)
+	sdk := &TyrAdsSdk{
+		config:        cfg,
+		httpClient:    client.NewHttpClient(cfg),
+		PrivacyMode:   enum.PrivacyModeRaw,
+		ConsentPolicy: contract.DefaultConsentPolicy,
 	}
+	sdk.httpClient.OnCircuitStateChange = func(from, to enum.CircuitState) {
+		if sdk.OnCircuitStateChange != nil {
+			sdk.OnCircuitStateChange(from, to)
+		}
+	}
+	return sdk
 }
 
 // Authenticate performs authentication using the provided request and returns an AuthenticationSign.
@@ -60,32 +117,175 @@ func NewTyrAdsSdk(apiKey, apiSecret, lang string) *TyrAdsSdk {
 //   - *AuthenticationSign: Contains the authentication token and user information
 //   - error: Returns an error if validation fails, request fails, or response parsing fails
 func (sdk *TyrAdsSdk) Authenticate(request AuthenticationRequest) (*AuthenticationSign, error) {
-	if err := request.ValidateAuthenticationRequest(); err != nil {
-		return nil, fmt.Errorf("validation error: %w", err)
+	return sdk.AuthenticateWithContext(context.Background(), request)
+}
+
+// AuthenticateWithContext behaves like Authenticate but binds the API call to ctx.
+// Errors wrap ErrValidation, ErrUserIDMapping, ErrRequest or ErrInvalidResponse depending on
+// the stage that failed. With UserIDMapper set, the API receives the pseudonymous ID
+// while the returned AuthenticationSign keeps the caller's publisher user ID.
+func (sdk *TyrAdsSdk) AuthenticateWithContext(ctx context.Context, request AuthenticationRequest) (*AuthenticationSign, error) {
+	omitted, err := sdk.prepareRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+	publisherUserID := request.PublisherUserID
+	if err := sdk.mapUserID(ctx, &request); err != nil {
+		return nil, err
 	}
 
-	data := request.GetParsedAuthenticationRequestData()
-	resp, err := sdk.httpClient.DoRequest("POST", "/auth", data)
+	data, event := sdk.payload(&request, "/auth", omitted)
+	resp, err := sdk.httpClient.DoRequestWithContext(ctx, "POST", "/auth", data)
+	if client.WasSent(err) {
+		sdk.reportDataSent(event)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("request error: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrRequest, err)
 	}
 
 	respMap, ok := resp.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("invalid response format")
+		return nil, fmt.Errorf("%w: invalid response format", ErrInvalidResponse)
 	}
 
 	dataMap, ok := respMap["data"].(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("invalid data format")
+		return nil, fmt.Errorf("%w: invalid data format", ErrInvalidResponse)
 	}
 
 	token, ok := dataMap["token"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid token format")
+		return nil, fmt.Errorf("%w: invalid token format", ErrInvalidResponse)
+	}
+
+	return contract.NewAuthenticationSign(token, publisherUserID), nil
+}
+
+// Fingerprint returns a fingerprint of the payload Authenticate would send for the request,
+// after deriving the age, applying ConsentPolicy, Config.AgePolicy, the phone number
+// normalization, UserIDMapper and the privacy mode. Requests sent as the same payload share a
+// fingerprint, and no raw personal data is hashed unless the privacy mode sends it raw.
+//
+// Parameters:
+//   - ctx: Context for the UserIDMapper call
+//   - request: AuthenticationRequest describing the user
+//
+// Returns:
+//   - string: The hex SHA-256 fingerprint, see AuthenticationRequest.Fingerprint
+//   - error: An error wrapping ErrValidation or ErrUserIDMapping, or an error if an Extra
+//     parameter cannot be encoded
+func (sdk *TyrAdsSdk) Fingerprint(ctx context.Context, request AuthenticationRequest) (string, error) {
+	if _, err := sdk.prepareRequest(&request); err != nil {
+		return "", err
+	}
+	if err := sdk.mapUserID(ctx, &request); err != nil {
+		return "", err
+	}
+	request.PrivacyMode = sdk.privacyMode(&request)
+	return request.Fingerprint()
+}
+
+// prepareRequest derives the age from the birth date and validates the request as of
+// Config.Now, applies ConsentPolicy, normalizes the phone number to E.164 and applies
+// Config.AgePolicy. It returns the payload keys dropped by the consent and age policies.
+// Errors wrap ErrValidation.
+func (sdk *TyrAdsSdk) prepareRequest(request *AuthenticationRequest) ([]string, error) {
+	now := sdk.config.Now()
+	request.DeriveAge(now)
+	if err := request.ValidateAuthenticationRequestAt(now); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	var dropped []string
+	if sdk.ConsentPolicy != nil {
+		dropped = sdk.ConsentPolicy(request)
+	}
+	if err := request.NormalizePhoneNumber(sdk.config.DefaultPhoneCountryCode); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	policy := sdk.config.AgePolicy
+	if policy == nil || request.Age == nil || *request.Age >= policy.MinimumAge {
+		return dropped, nil
+	}
+	if policy.DropMinorAge {
+		request.Age = nil
+		return append(dropped, "age"), nil
+	}
+	return nil, fmt.Errorf("%w: %w", ErrValidation, contract.ValidationErrors{{
+		Field:   "Age",
+		JSONKey: "age",
+		Rule:    contract.RuleMinimumAge,
+		Message: fmt.Sprintf("user must be at least %d years old", policy.MinimumAge),
+	}})
+}
+
+// mapUserID replaces the publisher user ID with its UserIDMapper pseudonym.
+func (sdk *TyrAdsSdk) mapUserID(ctx context.Context, request *AuthenticationRequest) error {
+	if sdk.UserIDMapper == nil {
+		return nil
+	}
+	externalID, err := sdk.UserIDMapper.ToExternal(ctx, request.PublisherUserID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIDMapping, err)
+	}
+	request.PublisherUserID = externalID
+	return nil
+}
+
+// launchUrlDestination is reported in DataSentEvent for signed launch URLs.
+const launchUrlDestination = "launch_url"
+
+// payload serializes the request and applies the privacy mode. It returns the event to
+// report through reportDataSent once the payload has been sent, listing dropped along
+// with the keys omitted by the privacy mode.
+func (sdk *TyrAdsSdk) payload(request *AuthenticationRequest, destination string, dropped []string) (map[string]interface{}, DataSentEvent) {
+	mode := sdk.privacyMode(request)
+	data := request.GetParsedAuthenticationRequestData()
+	hashed, omitted := contract.ApplyPrivacyMode(data, mode)
+	omitted = append(omitted, dropped...)
+	sort.Strings(omitted)
+
+	fields := make([]string, 0, len(data))
+	for key := range data {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return data, DataSentEvent{
+		Destination:     destination,
+		PublisherUserID: request.PublisherUserID,
+		PrivacyMode:     mode,
+		Fields:          fields,
+		Hashed:          hashed,
+		Omitted:         omitted,
+	}
+}
+
+// reportDataSent passes event to OnDataSent, if set.
+func (sdk *TyrAdsSdk) reportDataSent(event DataSentEvent) {
+	if sdk.OnDataSent != nil {
+		sdk.OnDataSent(event)
 	}
+}
+
+// privacyMode returns the privacy mode of the request, falling back to TyrAdsSdk.PrivacyMode.
+func (sdk *TyrAdsSdk) privacyMode(request *AuthenticationRequest) enum.PrivacyMode {
+	if request.PrivacyMode != "" {
+		return request.PrivacyMode
+	}
+	return sdk.PrivacyMode
+}
+
+// CircuitState returns the state of the API circuit breaker. It is always enum.CircuitClosed
+// unless Config.CircuitBreaker is set. Callers can use it to hide the offerwall entry point
+// while the API is unavailable.
+func (sdk *TyrAdsSdk) CircuitState() enum.CircuitState {
+	return sdk.httpClient.CircuitState()
+}
 
-	return contract.NewAuthenticationSign(token, request.PublisherUserID), nil
+// HedgeStats returns counters describing how often hedged /auth calls were sent and won.
+// It returns zero values unless Config.Hedging is set.
+func (sdk *TyrAdsSdk) HedgeStats() client.HedgeStats {
+	return sdk.httpClient.HedgeStats()
 }
 
 // IframeUrl generates a URL for an iframe integration with authentication.
@@ -161,3 +361,72 @@ func (sdk *TyrAdsSdk) IframePremiumWidget(authSignOrToken interface{}, name *str
 
 	return iframeUrl, nil
 }
+
+// SignedIframeUrl generates a short-lived, HMAC-signed launch URL without calling the API.
+// The URL carries the publisher user ID, issue and expiry timestamps, a random nonce and the
+// profile fields of the request, signed with the API secret.
+//
+// Experimental: signed launch is not part of the documented TyrAds API and only works if TyrAds
+// has enabled it for the publisher account. Because URLs end up in browser history, Referer
+// headers and access logs, email and phone number are always hashed, even in raw privacy mode.
+//
+// Parameters:
+//   - request: AuthenticationRequest describing the user
+//   - deeplinkTo: Optional pointer to a string specifying the target destination
+//
+// Returns:
+//   - string: The signed launch URL, valid for Config.LaunchUrlTTL
+//   - error: An error wrapping ErrValidation if the request is invalid or a profile field uses a
+//     reserved launch parameter, or an error if Config.LaunchUrlTTL is not positive or signing fails
+func (sdk *TyrAdsSdk) SignedIframeUrl(request AuthenticationRequest, deeplinkTo *string) (string, error) {
+	if sdk.config.LaunchUrlTTL <= 0 {
+		return "", fmt.Errorf("invalid LaunchUrlTTL %s: must be positive", sdk.config.LaunchUrlTTL)
+	}
+	omitted, err := sdk.prepareRequest(&request)
+	if err != nil {
+		return "", err
+	}
+	if deeplinkTo != nil && *deeplinkTo == "" {
+		return "", fmt.Errorf("invalid deeplinkTo argument: must be a non-empty string or nil")
+	}
+	if err := sdk.mapUserID(context.Background(), &request); err != nil {
+		return "", err
+	}
+
+	nonce, err := launch.NewNonce()
+	if err != nil {
+		return "", err
+	}
+
+	now := sdk.config.Now()
+	claims := launch.Claims{
+		ApiKey:          sdk.config.ApiKey,
+		PublisherUserID: request.PublisherUserID,
+		IssuedAt:        now,
+		ExpiresAt:       now.Add(sdk.config.LaunchUrlTTL),
+		Nonce:           nonce,
+		Profile:         map[string]string{},
+	}
+	if deeplinkTo != nil {
+		claims.DeeplinkTo = *deeplinkTo
+	}
+	if sdk.privacyMode(&request) == enum.PrivacyModeRaw {
+		request.PrivacyMode = enum.PrivacyModeHash
+	}
+	data, event := sdk.payload(&request, launchUrlDestination, omitted)
+	for key, value := range data {
+		if key != launch.ParamPublisherUserID {
+			claims.Profile[key] = fmt.Sprint(value)
+		}
+	}
+
+	launchUrl, err := launch.BuildUrl(sdk.config.IFrameBaseURL, sdk.config.ApiSecret, claims)
+	if errors.Is(err, launch.ErrReservedParam) {
+		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	sdk.reportDataSent(event)
+	return launchUrl, nil
+}
@@ -1,11 +1,23 @@
 package tyrads
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
 	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/enum"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/launch"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/userid"
 )
 
 func TestNewTyrAdsSdk(t *testing.T) {
@@ -163,6 +175,424 @@ func TestAuthenticate(t *testing.T) {
 		if result != nil {
 			t.Error("expected nil result when error occurs")
 		}
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected error to wrap ErrValidation, got %v", err)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+		}))
+		defer server.Close()
+
+		sdk := newTestSdk(server.URL)
+		result, err := sdk.AuthenticateWithContext(context.Background(), *contract.NewAuthenticationRequest("user123"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Token != "tok-123" || result.PublisherUserID != "user123" {
+			t.Errorf("unexpected sign: %+v", result)
+		}
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Invalid API key"}`))
+		}))
+		defer server.Close()
+
+		sdk := newTestSdk(server.URL)
+		_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+		if !errors.Is(err, ErrRequest) {
+			t.Fatalf("expected error to wrap ErrRequest, got %v", err)
+		}
+		var httpErr *client.HttpError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected HttpError with status 401, got %v", err)
+		}
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":{}}`))
+		}))
+		defer server.Close()
+
+		sdk := newTestSdk(server.URL)
+		_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+		if !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("expected error to wrap ErrInvalidResponse, got %v", err)
+		}
+	})
+}
+
+func TestAuthenticate_AgePolicy(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	adult := time.Date(2000, time.June, 16, 0, 0, 0, 0, time.UTC)
+	minor := time.Date(2014, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		policy      *config.AgePolicyConfig
+		birthDate   time.Time
+		expectedAge any
+		wantErr     bool
+	}{
+		{
+			name:        "derives age from birth date",
+			birthDate:   adult,
+			expectedAge: float64(23),
+		},
+		{
+			name:        "minor without policy",
+			birthDate:   minor,
+			expectedAge: float64(10),
+		},
+		{
+			name:      "minor rejected",
+			policy:    &config.AgePolicyConfig{MinimumAge: 13},
+			birthDate: minor,
+			wantErr:   true,
+		},
+		{
+			name:        "minor age dropped",
+			policy:      &config.AgePolicyConfig{MinimumAge: 13, DropMinorAge: true},
+			birthDate:   minor,
+			expectedAge: nil,
+		},
+		{
+			name:        "adult accepted",
+			policy:      &config.AgePolicyConfig{MinimumAge: 13},
+			birthDate:   adult,
+			expectedAge: float64(23),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = nil
+			sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+				c.SdkApiBaseURL = server.URL
+				c.AgePolicy = tt.policy
+				c.Now = func() time.Time { return now }
+			})
+
+			_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123", contract.WithBirthDate(tt.birthDate)))
+			if tt.wantErr {
+				var fieldErr *contract.FieldError
+				if !errors.Is(err, ErrValidation) || !errors.As(err, &fieldErr) || fieldErr.Rule != contract.RuleMinimumAge {
+					t.Fatalf("expected minimum age violation, got %v", err)
+				}
+				if body != nil {
+					t.Error("expected no API call for a rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body["age"] != tt.expectedAge {
+				t.Errorf("expected age %v, got %v", tt.expectedAge, body["age"])
+			}
+		})
+	}
+}
+
+func TestAuthenticate_BirthDateUsesConfigNow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.Now = func() time.Time { return now }
+	})
+
+	_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123", contract.WithBirthDate(now.AddDate(0, 0, 1))))
+	var fieldErr *contract.FieldError
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &fieldErr) || fieldErr.Rule != contract.RuleRange {
+		t.Errorf("expected a birth date after Config.Now to be rejected, got %v", err)
+	}
+}
+
+func TestAuthenticate_NormalizesPhoneNumber(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.DefaultPhoneCountryCode = "44"
+	})
+	request := contract.NewAuthenticationRequest("user123", contract.WithPhoneNumber("020 7946 0958"))
+	if _, err := sdk.Authenticate(*request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["phoneNumber"] != "+442079460958" {
+		t.Errorf("expected E.164 phone number, got %v", body["phoneNumber"])
+	}
+	if *request.PhoneNumber != "020 7946 0958" {
+		t.Errorf("expected caller's request to be left unchanged, got %s", *request.PhoneNumber)
+	}
+
+	national := contract.NewAuthenticationRequest("user123", contract.WithPhoneNumber("555-123-4567"))
+	if err := national.ValidateAuthenticationRequest(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if _, err := newTestSdk(server.URL).Authenticate(*national); err != nil {
+		t.Fatalf("expected national number without default country code to be accepted, got %v", err)
+	}
+	if body["phoneNumber"] != "5551234567" {
+		t.Errorf("expected national digits, got %v", body["phoneNumber"])
+	}
+}
+
+func TestAuthenticate_PrivacyMode(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	var events []DataSentEvent
+	sdk := newTestSdk(server.URL)
+	sdk.PrivacyMode = enum.PrivacyModeHash
+	sdk.OnDataSent = func(event DataSentEvent) { events = append(events, event) }
+
+	tests := []struct {
+		name          string
+		opts          []contract.AuthenticationRequestOptions
+		expectedEmail any
+		expectedMode  enum.PrivacyMode
+	}{
+		{
+			name:          "config mode",
+			expectedEmail: contract.HashPersonalData("alice@example.com"),
+			expectedMode:  enum.PrivacyModeHash,
+		},
+		{
+			name:          "request override",
+			opts:          []contract.AuthenticationRequestOptions{contract.WithPrivacyMode(enum.PrivacyModeOmit)},
+			expectedEmail: nil,
+			expectedMode:  enum.PrivacyModeOmit,
+		},
+		{
+			name:          "request opt out",
+			opts:          []contract.AuthenticationRequestOptions{contract.WithPrivacyMode(enum.PrivacyModeRaw)},
+			expectedEmail: "alice@example.com",
+			expectedMode:  enum.PrivacyModeRaw,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			opts := append([]contract.AuthenticationRequestOptions{contract.WithEmail("alice@example.com")}, tt.opts...)
+			if _, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123", opts...)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body["email"] != tt.expectedEmail {
+				t.Errorf("expected email %v, got %v", tt.expectedEmail, body["email"])
+			}
+			if len(events) != 1 || events[0].Destination != "/auth" || events[0].PrivacyMode != tt.expectedMode {
+				t.Fatalf("expected one /auth audit event, got %+v", events)
+			}
+			for _, field := range events[0].Fields {
+				if _, ok := body[field]; !ok {
+					t.Errorf("audit event reports field %s that was not sent", field)
+				}
+			}
+			if len(events[0].Fields) != len(body) {
+				t.Errorf("expected audit fields %v to match sent body %v", events[0].Fields, body)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_OnDataSentOnlyForSentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	var events []DataSentEvent
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+		c.RateLimit = &config.RateLimitConfig{RateLimit: config.RateLimit{RequestsPerSecond: 0.001, Burst: 1}, FailFast: true}
+	})
+	sdk.OnDataSent = func(event DataSentEvent) { events = append(events, event) }
+
+	if _, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123")); !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected only the sent request to be reported, got %d events", len(events))
+	}
+}
+
+func TestAuthenticate_ConsentPolicy(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	request := contract.NewAuthenticationRequest("user123",
+		contract.WithAge(30),
+		contract.WithEmail("alice@example.com"),
+		contract.WithGdprConsent(""),
+		contract.WithRegion("DE"),
+	)
+
+	var events []DataSentEvent
+	audited := newTestSdk(server.URL)
+	audited.OnDataSent = func(event DataSentEvent) { events = append(events, event) }
+	if _, err := audited.Authenticate(*request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := body["email"]; ok {
+		t.Errorf("expected email to be stripped without consent, got %v", body)
+	}
+	if len(events) != 1 || !reflect.DeepEqual(events[0].Omitted, []string{"age", "email"}) {
+		t.Errorf("expected stripped keys in the audit event, got %+v", events)
+	}
+	if _, ok := body["age"]; ok {
+		t.Errorf("expected age to be stripped without consent, got %v", body)
+	}
+	if body["gdprApplies"] != true || body["region"] != "DE" {
+		t.Errorf("expected consent fields to be sent, got %v", body)
+	}
+	if request.Email == nil {
+		t.Error("expected caller's request to be left unchanged")
+	}
+
+	sdk := newTestSdk(server.URL)
+	sdk.ConsentPolicy = nil
+	if _, err := sdk.Authenticate(*request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["email"] != "alice@example.com" {
+		t.Errorf("expected email to be sent without a consent policy, got %v", body)
+	}
+}
+
+func TestAuthenticate_UserIDMapper(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{"token":"tok-123"}}`))
+	}))
+	defer server.Close()
+
+	mapper, err := userid.NewHMACMapper([]byte("local-key-0123456789"), userid.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sdk := newTestSdk(server.URL)
+	sdk.UserIDMapper = mapper
+
+	sign, err := sdk.Authenticate(*contract.NewAuthenticationRequest("internal-42"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sign.PublisherUserID != "internal-42" {
+		t.Errorf("expected sign to keep the internal ID, got %s", sign.PublisherUserID)
+	}
+	externalID, _ := body["publisherUserId"].(string)
+	if externalID == "" || externalID == "internal-42" {
+		t.Fatalf("expected a pseudonymous ID to be sent, got %v", body["publisherUserId"])
+	}
+	if internalID, err := mapper.ToInternal(context.Background(), externalID); err != nil || internalID != "internal-42" {
+		t.Errorf("expected reverse mapping to internal-42, got %s, %v", internalID, err)
+	}
+
+	launchUrl, err := sdk.SignedIframeUrl(*contract.NewAuthenticationRequest("internal-42"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(launchUrl, "internal-42") || !strings.Contains(launchUrl, externalID) {
+		t.Errorf("expected launch URL to carry the pseudonymous ID, got %s", launchUrl)
+	}
+
+	fail := func(ctx context.Context, id string) (string, error) { return "", errors.New("store down") }
+	failing := newTestSdk(server.URL)
+	if failing.UserIDMapper, err = userid.NewMapperFuncs(fail, fail); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := failing.Authenticate(*contract.NewAuthenticationRequest("internal-42")); !errors.Is(err, ErrUserIDMapping) {
+		t.Errorf("expected ErrUserIDMapping, got %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	sdk := newTestSdk("http://localhost")
+	fingerprint := func(sdk *TyrAdsSdk, request *contract.AuthenticationRequest) string {
+		t.Helper()
+		f, err := sdk.Fingerprint(context.Background(), *request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return f
+	}
+
+	withoutConsent := fingerprint(sdk, contract.NewAuthenticationRequest("user123", contract.WithGdprConsent(""), contract.WithRegion("DE")))
+	if got := fingerprint(sdk, contract.NewAuthenticationRequest("user123", contract.WithGdprConsent(""), contract.WithRegion("DE"), contract.WithEmail("alice@example.com"))); got != withoutConsent {
+		t.Error("expected a consent-stripped email not to change the fingerprint")
+	}
+
+	request := contract.NewAuthenticationRequest("user123", contract.WithEmail("alice@example.com"))
+	raw := fingerprint(sdk, request)
+	hashing := newTestSdk("http://localhost")
+	hashing.PrivacyMode = enum.PrivacyModeHash
+	hashed := fingerprint(hashing, request)
+	if hashed == raw {
+		t.Error("expected the privacy mode to change the fingerprint")
+	}
+	expected, _ := contract.NewAuthenticationRequest("user123", contract.WithEmail(contract.HashPersonalData("alice@example.com"))).Fingerprint()
+	if hashed != expected {
+		t.Errorf("expected the fingerprint of the hashed payload %s, got %s", expected, hashed)
+	}
+
+	mapped := newTestSdk("http://localhost")
+	mapper, err := userid.NewHMACMapper([]byte("local-key-0123456789"), userid.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mapped.UserIDMapper = mapper
+	if fingerprint(mapped, request) == raw {
+		t.Error("expected the mapped user ID to change the fingerprint")
+	}
+
+	if _, err := sdk.Fingerprint(context.Background(), *contract.NewAuthenticationRequest("")); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+}
+
+func newTestSdk(baseURL string) *TyrAdsSdk {
+	return NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = baseURL
 	})
 }
 
@@ -232,3 +662,122 @@ func TestIframePremiumWidget(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedIframeUrl(t *testing.T) {
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.LaunchUrlTTL = time.Minute
+	})
+	request := contract.NewAuthenticationRequest("user123", contract.WithAge(25))
+
+	launchUrl, err := sdk.SignedIframeUrl(*request, stringPtr("offers"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(launchUrl, "https://sdk.tyrads.com?") {
+		t.Errorf("unexpected url: %s", launchUrl)
+	}
+
+	claims, err := launch.VerifyUrl(launchUrl, "test-secret", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected verification error: %v", err)
+	}
+	if claims.PublisherUserID != "user123" || claims.DeeplinkTo != "offers" || claims.Profile["age"] != "25" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if claims.ExpiresAt.Sub(claims.IssuedAt) != time.Minute {
+		t.Errorf("expected 1 minute validity, got %s", claims.ExpiresAt.Sub(claims.IssuedAt))
+	}
+
+	if _, err := launch.VerifyUrl(launchUrl, "test-secret", time.Now().Add(2*time.Minute)); !errors.Is(err, launch.ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+
+	if _, err := sdk.SignedIframeUrl(*contract.NewAuthenticationRequest(""), nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestSignedIframeUrl_PersonalData(t *testing.T) {
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en")
+
+	tests := []struct {
+		name          string
+		opts          []contract.AuthenticationRequestOptions
+		expectedEmail string
+	}{
+		{name: "raw mode is hashed", expectedEmail: contract.HashPersonalData("user@example.com")},
+		{name: "omit mode", opts: []contract.AuthenticationRequestOptions{contract.WithPrivacyMode(enum.PrivacyModeOmit)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]contract.AuthenticationRequestOptions{contract.WithEmail("user@example.com")}, tt.opts...)
+			launchUrl, err := sdk.SignedIframeUrl(*contract.NewAuthenticationRequest("user123", opts...), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(launchUrl, "example.com") {
+				t.Errorf("expected no raw email in %s", launchUrl)
+			}
+			claims, err := launch.VerifyUrl(launchUrl, "test-secret", time.Now())
+			if err != nil {
+				t.Fatalf("unexpected verification error: %v", err)
+			}
+			if claims.Profile["email"] != tt.expectedEmail {
+				t.Errorf("expected email %q, got %q", tt.expectedEmail, claims.Profile["email"])
+			}
+		})
+	}
+}
+
+func TestSignedIframeUrl_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		ttl            time.Duration
+		request        *contract.AuthenticationRequest
+		expectedErr    error
+		expectedErrMsg string
+	}{
+		{name: "zero ttl", ttl: 0, request: contract.NewAuthenticationRequest("user123"), expectedErrMsg: "invalid LaunchUrlTTL 0s: must be positive"},
+		{name: "reserved extra param", ttl: time.Minute, request: contract.NewAuthenticationRequest("user123", contract.WithExtraParam("sig", "forged")), expectedErr: launch.ErrReservedParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+				c.LaunchUrlTTL = tt.ttl
+			})
+			_, err := sdk.SignedIframeUrl(*tt.request, nil)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if tt.expectedErr != nil && (!errors.Is(err, tt.expectedErr) || !errors.Is(err, ErrValidation)) {
+				t.Errorf("expected %v wrapped in ErrValidation, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErrMsg != "" && err.Error() != tt.expectedErrMsg {
+				t.Errorf("expected error '%s', got '%s'", tt.expectedErrMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestCircuitState(t *testing.T) {
+	sdk := NewTyrAdsSdk("test-key", "test-secret", "en")
+	if state := sdk.CircuitState(); state != enum.CircuitClosed {
+		t.Errorf("expected %s without breaker, got %s", enum.CircuitClosed, state)
+	}
+
+	sdk = NewTyrAdsSdk("test-key", "test-secret", "en", func(c *config.Config) {
+		c.SdkApiBaseURL = "http://127.0.0.1:0"
+		c.CircuitBreaker = &config.CircuitBreakerConfig{MinRequests: 1}
+	})
+	var transitions []enum.CircuitState
+	sdk.OnCircuitStateChange = func(from, to enum.CircuitState) { transitions = append(transitions, to) }
+	sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+	if state := sdk.CircuitState(); state != enum.CircuitOpen {
+		t.Errorf("expected %s after failure, got %s", enum.CircuitOpen, state)
+	}
+	if len(transitions) != 1 || transitions[0] != enum.CircuitOpen {
+		t.Errorf("expected one transition to %s, got %v", enum.CircuitOpen, transitions)
+	}
+}
@@ -0,0 +1,315 @@
+package tyradstest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+// RecorderMode selects whether a Recorder records real interactions or replays a cassette.
+type RecorderMode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network.
+	ModeReplay RecorderMode = iota
+	// ModeRecord forwards requests to the real transport and records them.
+	ModeRecord
+)
+
+// Redacted replaces sensitive values in recorded cassettes.
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned in replay mode when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest holds the parts of a request used for matching. API credentials are
+// never recorded because request headers are not stored.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded response.
+type CassetteResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records interactions into a cassette file or
+// replays them offline. Requests are matched on method, path and normalized body.
+type Recorder struct {
+	// Transport sends requests in record mode. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// RedactFields lists JSON fields and query parameters replaced by Redacted in recorded
+	// requests and responses, at any depth. Defaults to contract.SensitiveDataKeys, the personal,
+	// profile and consent fields of AuthenticationRequest.
+	RedactFields []string
+	// PseudonymizeFields lists JSON fields and query parameters replaced by a hash of their value,
+	// so requests for different users still match different recordings. Defaults to publisherUserId.
+	PseudonymizeFields []string
+
+	mode     RecorderMode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+type RecorderOptions func(*Recorder)
+
+// NewRecorder creates a Recorder for the cassette at path. In replay mode the cassette is loaded
+// immediately and must exist.
+func NewRecorder(path string, mode RecorderMode, opts ...RecorderOptions) (*Recorder, error) {
+	r := &Recorder{
+		Transport:          http.DefaultTransport,
+		RedactFields:       contract.SensitiveDataKeys(),
+		PseudonymizeFields: []string{"publisherUserId"},
+		mode:               mode,
+		path:               path,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette: %w", err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// ConfigOption returns a config option installing the recorder as the SDK transport.
+func (r *Recorder) ConfigOption() config.ConfigOptions {
+	return func(c *config.Config) {
+		c.Transport = r
+	}
+}
+
+// RoundTrip implements http.RoundTripper. The caller's request is not modified; in record
+// mode a clone carrying the buffered body is forwarded to Transport.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	key := CassetteRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  r.normalizeQuery(req.URL.RawQuery),
+		Body:   r.normalizeBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		recorded, err := r.find(key)
+		if err != nil {
+			return nil, err
+		}
+		return recorded.toHttpResponse(req), nil
+	}
+
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	resp, err := r.Transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.Request = req
+
+	recorded := CassetteResponse{
+		StatusCode: resp.StatusCode,
+		Header:     map[string]string{},
+		Body:       r.normalizeBody(respBody),
+	}
+	for _, h := range []string{"Content-Type", "Retry-After"} {
+		if v := resp.Header.Get(h); v != "" {
+			recorded.Header[h] = v
+		}
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: key, Response: recorded})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It is a no-op in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, b, 0o644)
+}
+
+// find returns the first unused interaction matching key, falling back to the last used match
+// so that repeated identical calls can be replayed from a single recording.
+func (r *Recorder) find(key CassetteRequest) (CassetteResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request != key {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return in.Response, nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return r.cassette.Interactions[last].Response, nil
+	}
+	return CassetteResponse{}, fmt.Errorf("%w: %s %s", ErrNoInteraction, key.Method, key.Path)
+}
+
+// normalizeBody redacts and pseudonymizes the configured fields at any depth and re-encodes
+// JSON bodies with sorted keys. Bodies that are not JSON are kept as-is.
+func (r *Recorder) normalizeBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return string(body)
+	}
+	b, err := json.Marshal(r.redact(data))
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+// normalizeQuery redacts and pseudonymizes the configured query parameters. Queries without
+// such parameters are kept as-is.
+func (r *Recorder) normalizeQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	changed := false
+	for key, vs := range values {
+		for i, v := range vs {
+			if replaced, ok := r.replacement(key, v); ok {
+				vs[i] = replaced
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+func (r *Recorder) redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok {
+				if replaced, ok := r.replacement(key, s); ok {
+					v[key] = replaced
+					continue
+				}
+			} else if slices.Contains(r.RedactFields, key) && value != nil {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = r.redact(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = r.redact(value)
+		}
+	}
+	return v
+}
+
+// replacement returns the recorded value of a string field, and whether it is replaced.
+func (r *Recorder) replacement(key, value string) (string, bool) {
+	if slices.Contains(r.RedactFields, key) {
+		return Redacted, true
+	}
+	if slices.Contains(r.PseudonymizeFields, key) {
+		sum := sha256.Sum256([]byte(value))
+		return Redacted + "-" + hex.EncodeToString(sum[:8]), true
+	}
+	return "", false
+}
+
+func (cr CassetteResponse) toHttpResponse(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range cr.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		StatusCode:    cr.StatusCode,
+		Status:        fmt.Sprintf("%d %s", cr.StatusCode, http.StatusText(cr.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(cr.Body)),
+		ContentLength: int64(len(cr.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,177 @@
+package tyradstest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassettes", "auth.json")
+
+	server := NewServer(t)
+	recorder, err := NewRecorder(cassettePath, ModeRecord)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sdk := server.Sdk(recorder.ConfigOption())
+
+	sign, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123", contract.WithEmail("alice@example.com")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+	server.Close()
+
+	raw, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{DefaultApiKey, DefaultApiSecret, "alice@example.com"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("cassette must not contain %q", secret)
+		}
+	}
+
+	replayer, err := NewRecorder(cassettePath, ModeReplay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	offline := tyrads.NewTyrAdsSdk("other-key", "other-secret", "en", replayer.ConfigOption(), func(c *config.Config) {
+		c.SdkApiBaseURL = "http://offline.invalid"
+	})
+
+	replayed, err := offline.Authenticate(*contract.NewAuthenticationRequest("user123", contract.WithEmail("bob@example.com")))
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if replayed.Token != sign.Token {
+		t.Errorf("expected replayed token %s, got %s", sign.Token, replayed.Token)
+	}
+
+	_, err = offline.Authenticate(*contract.NewAuthenticationRequest("user456"))
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction for unrecorded request, got %v", err)
+	}
+}
+
+func TestRecorder_ReplayMissingCassette(t *testing.T) {
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+}
+
+func TestRecorder_NormalizeBody(t *testing.T) {
+	r, _ := NewRecorder("", ModeRecord)
+
+	a := r.normalizeBody([]byte(`{"publisherUserId":"u1","email":"a@b.co","age":25,"sub1":"s"}`))
+	b := r.normalizeBody([]byte(`{"age":31,"sub1":"s","email":"x@y.co","publisherUserId":"u1"}`))
+	if a != b {
+		t.Errorf("expected equal normalized bodies, got %s and %s", a, b)
+	}
+	if expected := `{"age":"REDACTED","email":"REDACTED","publisherUserId":"REDACTED-` + pseudonym("u1") + `","sub1":"s"}`; a != expected {
+		t.Errorf("expected %s, got %s", expected, a)
+	}
+	if got := r.normalizeBody([]byte("not json")); got != "not json" {
+		t.Errorf("expected non-JSON body to be kept, got %s", got)
+	}
+}
+
+func pseudonym(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+func TestRecorder_Redaction(t *testing.T) {
+	r, _ := NewRecorder("", ModeRecord)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "nested", body: `{"data":{"email":"a@b.co","token":"t"}}`, expected: `{"data":{"email":"REDACTED","token":"t"}}`},
+		{name: "array", body: `[{"phoneNumber":"+441234567"}]`, expected: `[{"phoneNumber":"REDACTED"}]`},
+		{name: "non-string", body: `{"email":{"value":"a@b.co"}}`, expected: `{"email":"REDACTED"}`},
+		{
+			name:     "profile and consent",
+			body:     `{"age":30,"birthDate":"1990-05-01","childDirected":false,"gdprApplies":true,"gender":1,"limitAdTracking":false,"region":"DE","tcfConsent":"CPc","usPrivacy":"1YNN"}`,
+			expected: `{"age":"REDACTED","birthDate":"REDACTED","childDirected":"REDACTED","gdprApplies":"REDACTED","gender":"REDACTED","limitAdTracking":"REDACTED","region":"REDACTED","tcfConsent":"REDACTED","usPrivacy":"REDACTED"}`,
+		},
+		{name: "different users", body: `{"publisherUserId":"u2"}`, expected: `{"publisherUserId":"REDACTED-` + pseudonym("u2") + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.normalizeBody([]byte(tt.body)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if got, expected := r.normalizeQuery("publisherUserId=u1&email=a%40b.co&page=2"), "email=REDACTED&page=2&publisherUserId=REDACTED-"+pseudonym("u1"); got != expected {
+		t.Errorf("expected query %s, got %s", expected, got)
+	}
+	if got := r.normalizeQuery("b=2&a=1"); got != "b=2&a=1" {
+		t.Errorf("expected query without redacted fields to be kept, got %s", got)
+	}
+}
+
+func TestRecorder_RecordsRedactedResponse(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"token":"tok","publisherUserId":"user123","email":"alice@example.com"}}`))
+	}))
+	defer server.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "auth.json")
+	recorder, _ := NewRecorder(cassettePath, ModeRecord)
+
+	body := `{"publisherUserId":"user123","email":"alice@example.com"}`
+	req, _ := http.NewRequest("POST", server.URL+"/auth", strings.NewReader(body))
+	originalBody := req.Body
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if req.Body != originalBody {
+		t.Error("expected the caller's request body not to be replaced")
+	}
+	if resp.Request != req {
+		t.Error("expected the response to reference the caller's request")
+	}
+	if received != body {
+		t.Errorf("expected server to receive %s, got %s", body, received)
+	}
+	if !strings.Contains(string(respBody), "alice@example.com") {
+		t.Errorf("expected caller to receive the unredacted response, got %s", respBody)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+	raw, _ := os.ReadFile(cassettePath)
+	for _, secret := range []string{"alice@example.com", "user123"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("cassette must not contain %q", secret)
+		}
+	}
+}
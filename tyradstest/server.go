@@ -0,0 +1,251 @@
+package tyradstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+)
+
+// Default credentials accepted by the fake server.
+const (
+	DefaultApiKey    = "test-key"
+	DefaultApiSecret = "test-secret"
+)
+
+// RecordedRequest is a request received by the fake server.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Query  map[string]string
+	Body   map[string]interface{}
+	Time   time.Time
+}
+
+// Response is a scripted reply returned instead of the normal behaviour of the server.
+type Response struct {
+	StatusCode int
+	Body       string
+	Header     map[string]string
+	Delay      time.Duration
+}
+
+// Server is a fake TyrAds API. The /auth endpoint verifies the X-API-Key and X-API-Secret
+// headers and issues deterministic tokens.
+type Server struct {
+	*httptest.Server
+
+	// ApiKey and ApiSecret are the credentials the server accepts.
+	ApiKey    string
+	ApiSecret string
+	// Latency is added before every response.
+	Latency time.Duration
+	// TokenFunc builds the token returned for an authentication payload.
+	// Defaults to "token-" followed by the publisher user ID.
+	TokenFunc func(body map[string]interface{}) string
+
+	mu       sync.Mutex
+	script   []Response
+	requests []RecordedRequest
+}
+
+type ServerOptions func(*Server)
+
+// NewServer starts a fake TyrAds API server that is closed when the test ends.
+func NewServer(t testing.TB, opts ...ServerOptions) *Server {
+	s := &Server{
+		ApiKey:    DefaultApiKey,
+		ApiSecret: DefaultApiSecret,
+		TokenFunc: func(body map[string]interface{}) string {
+			return fmt.Sprintf("token-%v", body["publisherUserId"])
+		},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Sdk returns a TyrAdsSdk using the server credentials and pointed at the server.
+func (s *Server) Sdk(opts ...config.ConfigOptions) *tyrads.TyrAdsSdk {
+	opts = append([]config.ConfigOptions{func(c *config.Config) {
+		c.SdkApiBaseURL = s.URL
+	}}, opts...)
+	return tyrads.NewTyrAdsSdk(s.ApiKey, s.ApiSecret, "en", opts...)
+}
+
+// Enqueue scripts the next responses. Each scripted response is used once, in order,
+// before the server falls back to its normal behaviour.
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// FailNext makes the next n requests fail with status and message.
+func (s *Server) FailNext(n, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	for i := 0; i < n; i++ {
+		s.Enqueue(Response{StatusCode: status, Body: string(body)})
+	}
+}
+
+// Requests returns a copy of the requests received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// AuthRequests returns the requests received on the /auth endpoint.
+func (s *Server) AuthRequests() []RecordedRequest {
+	var auth []RecordedRequest
+	for _, r := range s.Requests() {
+		if strings.HasSuffix(r.Path, "/auth") {
+			auth = append(auth, r)
+		}
+	}
+	return auth
+}
+
+// Reset clears recorded requests and pending scripted responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = nil
+	s.requests = nil
+}
+
+// AssertAuthCount fails the test unless the /auth endpoint was called n times.
+func (s *Server) AssertAuthCount(t testing.TB, n int) {
+	t.Helper()
+	if got := len(s.AuthRequests()); got != n {
+		t.Errorf("expected %d auth requests, got %d", n, got)
+	}
+}
+
+// AssertAuthenticated fails the test unless an /auth request was made for publisherUserID.
+func (s *Server) AssertAuthenticated(t testing.TB, publisherUserID string) {
+	t.Helper()
+	for _, r := range s.AuthRequests() {
+		if r.Body["publisherUserId"] == publisherUserID {
+			return
+		}
+	}
+	t.Errorf("expected an auth request for publisher user %q", publisherUserID)
+}
+
+// AssertLastAuthField fails the test unless the last /auth payload has key set to value.
+// Numbers are compared after JSON decoding, so integers must be given as float64.
+func (s *Server) AssertLastAuthField(t testing.TB, key string, value interface{}) {
+	t.Helper()
+	auth := s.AuthRequests()
+	if len(auth) == 0 {
+		t.Errorf("expected an auth request, got none")
+		return
+	}
+	if got, ok := auth[len(auth)-1].Body[key]; !ok || got != value {
+		t.Errorf("expected auth field %s to be %v, got %v", key, value, got)
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := s.record(r)
+
+	s.mu.Lock()
+	latency := s.Latency
+	var scripted *Response
+	if len(s.script) > 0 {
+		scripted = &s.script[0]
+		s.script = s.script[1:]
+	}
+	s.mu.Unlock()
+
+	if scripted != nil {
+		latency += scripted.Delay
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if scripted != nil {
+		for k, v := range scripted.Header {
+			w.Header().Set(k, v)
+		}
+		status := scripted.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, scripted.Body)
+		return
+	}
+
+	if r.Header.Get("X-API-Key") != s.ApiKey || r.Header.Get("X-API-Secret") != s.ApiSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"message": "Invalid API key"})
+		return
+	}
+
+	if !strings.HasSuffix(r.URL.Path, "/auth") {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not found"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"message": "Method not allowed"})
+		return
+	}
+
+	body := rec.Body
+	if id, _ := body["publisherUserId"].(string); id == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "publisherUserId is required"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    map[string]interface{}{"token": s.TokenFunc(body)},
+	})
+}
+
+func (s *Server) record(r *http.Request) RecordedRequest {
+	rec := RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Query:  map[string]string{},
+		Time:   time.Now(),
+	}
+	for k := range r.URL.Query() {
+		rec.Query[k] = r.URL.Query().Get(k)
+	}
+	if b, err := io.ReadAll(r.Body); err == nil && len(b) > 0 {
+		json.Unmarshal(b, &rec.Body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, rec)
+	return rec
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,93 @@
+package tyradstest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	tyrads "github.com/tyrads-com/tyrads-go-sdk-iframe"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/client"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/config"
+	"github.com/tyrads-com/tyrads-go-sdk-iframe/contract"
+)
+
+func TestServer_Authenticate(t *testing.T) {
+	server := NewServer(t)
+	sdk := server.Sdk()
+
+	sign, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123", contract.WithAge(25)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sign.Token != "token-user123" {
+		t.Errorf("expected token 'token-user123', got '%s'", sign.Token)
+	}
+
+	server.AssertAuthCount(t, 1)
+	server.AssertAuthenticated(t, "user123")
+	server.AssertLastAuthField(t, "age", float64(25))
+
+	req := server.AuthRequests()[0]
+	if req.Path != "/v3.0/auth" || req.Query["lang"] != "en" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.Header.Get("X-API-Key") != DefaultApiKey {
+		t.Errorf("expected API key header to be recorded")
+	}
+}
+
+func TestServer_RejectsInvalidCredentials(t *testing.T) {
+	server := NewServer(t)
+	sdk := tyrads.NewTyrAdsSdk("wrong-key", DefaultApiSecret, "en", func(c *config.Config) {
+		c.SdkApiBaseURL = server.URL
+	})
+
+	_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+	var httpErr *client.HttpError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 HttpError, got %v", err)
+	}
+}
+
+func TestServer_ScriptedFailures(t *testing.T) {
+	server := NewServer(t, func(s *Server) {
+		s.TokenFunc = func(body map[string]interface{}) string { return "fixed" }
+	})
+	server.FailNext(1, http.StatusServiceUnavailable, "maintenance")
+	server.Enqueue(Response{StatusCode: http.StatusOK, Body: `{"data":{"token":"scripted"}}`})
+	sdk := server.Sdk()
+
+	_, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+	if err == nil || !errors.Is(err, tyrads.ErrRequest) {
+		t.Fatalf("expected scripted failure, got %v", err)
+	}
+
+	sign, err := sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+	if err != nil || sign.Token != "scripted" {
+		t.Fatalf("expected scripted token, got %v, %v", sign, err)
+	}
+
+	sign, err = sdk.Authenticate(*contract.NewAuthenticationRequest("user123"))
+	if err != nil || sign.Token != "fixed" {
+		t.Fatalf("expected custom token, got %v, %v", sign, err)
+	}
+	server.AssertAuthCount(t, 3)
+
+	server.Reset()
+	server.AssertAuthCount(t, 0)
+}
+
+func TestServer_Latency(t *testing.T) {
+	server := NewServer(t, func(s *Server) { s.Latency = 100 * time.Millisecond })
+	sdk := server.Sdk()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := sdk.AuthenticateWithContext(ctx, *contract.NewAuthenticationRequest("user123"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package userid maps internal user IDs to the pseudonymous publisher user IDs sent to
+// TyrAds, and back when a postback arrives.
+package userid
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned when a publisher user ID has no known internal user ID.
+	ErrNotFound = errors.New("user ID not found")
+	// ErrNilFunc is returned by NewMapperFuncs when a lookup function is missing.
+	ErrNilFunc = errors.New("mapper function is nil")
+	// ErrShortKey is returned by NewHMACMapper when the key is shorter than MinHMACKeyLength.
+	ErrShortKey = errors.New("HMAC key is too short")
+	// ErrNilStore is returned by NewHMACMapper when the store is missing.
+	ErrNilStore = errors.New("store is nil")
+)
+
+// MinHMACKeyLength is the shortest key accepted by NewHMACMapper, in bytes.
+const MinHMACKeyLength = 16
+
+// Mapper converts internal user IDs to publisher user IDs and back.
+type Mapper interface {
+	// ToExternal returns the publisher user ID sent to TyrAds for internalID.
+	ToExternal(ctx context.Context, internalID string) (string, error)
+	// ToInternal returns the internal user ID of externalID, or an error wrapping ErrNotFound.
+	ToInternal(ctx context.Context, externalID string) (string, error)
+}
+
+// Store keeps the reverse mapping from publisher user IDs to internal user IDs.
+type Store interface {
+	// Save records that externalID belongs to internalID. Saving an existing pair again must succeed.
+	Save(ctx context.Context, externalID, internalID string) error
+	// Lookup returns the internal user ID of externalID, or an error wrapping ErrNotFound.
+	Lookup(ctx context.Context, externalID string) (string, error)
+}
+
+// HMACMapper derives publisher user IDs as the HMAC-SHA256 of the internal ID, keyed with a
+// local secret that never leaves the process. The same internal ID always maps to the same
+// publisher user ID, and the reverse mapping is kept in a Store.
+type HMACMapper struct {
+	key   []byte
+	store Store
+}
+
+// NewHMACMapper creates an HMACMapper. The key must stay the same for publisher user IDs to stay stable.
+// It returns ErrShortKey if the key has fewer than MinHMACKeyLength bytes and ErrNilStore if store is nil.
+func NewHMACMapper(key []byte, store Store) (*HMACMapper, error) {
+	if len(key) < MinHMACKeyLength {
+		return nil, fmt.Errorf("%w: got %d bytes, need at least %d", ErrShortKey, len(key), MinHMACKeyLength)
+	}
+	if store == nil {
+		return nil, ErrNilStore
+	}
+	return &HMACMapper{key: key, store: store}, nil
+}
+
+// ToExternal returns the hex encoded first 16 bytes of the HMAC of internalID and saves the pair.
+func (m *HMACMapper) ToExternal(ctx context.Context, internalID string) (string, error) {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(internalID))
+	externalID := hex.EncodeToString(mac.Sum(nil)[:16])
+	if err := m.store.Save(ctx, externalID, internalID); err != nil {
+		return "", err
+	}
+	return externalID, nil
+}
+
+// ToInternal looks externalID up in the store.
+func (m *HMACMapper) ToInternal(ctx context.Context, externalID string) (string, error) {
+	return m.store.Lookup(ctx, externalID)
+}
+
+// MapperFuncs adapts a pair of lookup functions, such as queries against a user table, to a Mapper.
+type MapperFuncs struct {
+	external func(ctx context.Context, internalID string) (string, error)
+	internal func(ctx context.Context, externalID string) (string, error)
+}
+
+// NewMapperFuncs creates a MapperFuncs from its two lookup functions. It returns ErrNilFunc
+// if either is nil, since both directions are needed: ToExternal when sending requests and
+// ToInternal when postbacks arrive.
+func NewMapperFuncs(external, internal func(ctx context.Context, id string) (string, error)) (*MapperFuncs, error) {
+	if external == nil {
+		return nil, fmt.Errorf("%w: external", ErrNilFunc)
+	}
+	if internal == nil {
+		return nil, fmt.Errorf("%w: internal", ErrNilFunc)
+	}
+	return &MapperFuncs{external: external, internal: internal}, nil
+}
+
+func (m *MapperFuncs) ToExternal(ctx context.Context, internalID string) (string, error) {
+	return m.external(ctx, internalID)
+}
+
+func (m *MapperFuncs) ToInternal(ctx context.Context, externalID string) (string, error) {
+	return m.internal(ctx, externalID)
+}
+
+// MemoryStore is a Store kept in memory, for tests and single-instance deployments.
+type MemoryStore struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ids: map[string]string{}}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, externalID, internalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[externalID] = internalID
+	return nil
+}
+
+func (s *MemoryStore) Lookup(ctx context.Context, externalID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	internalID, ok := s.ids[externalID]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return internalID, nil
+}
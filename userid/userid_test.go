@@ -0,0 +1,128 @@
+package userid
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHMACMapper(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	mapper, err := NewHMACMapper([]byte("local-key-0123456789"), store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	external, err := mapper.ToExternal(ctx, "internal-42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(external) != 32 || strings.Contains(external, "42") {
+		t.Errorf("expected 32 hex characters not leaking the internal ID, got %s", external)
+	}
+
+	again, _ := mapper.ToExternal(ctx, "internal-42")
+	if again != external {
+		t.Errorf("expected stable mapping, got %s and %s", external, again)
+	}
+	otherMapper, _ := NewHMACMapper([]byte("other-key-0123456789"), store)
+	other, _ := otherMapper.ToExternal(ctx, "internal-42")
+	if other == external {
+		t.Error("expected a different key to produce a different ID")
+	}
+
+	internal, err := mapper.ToInternal(ctx, external)
+	if err != nil || internal != "internal-42" {
+		t.Errorf("expected internal-42, got %s, %v", internal, err)
+	}
+	if _, err := mapper.ToInternal(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestHMACMapper_StoreError(t *testing.T) {
+	storeErr := errors.New("store down")
+	mapper, err := NewHMACMapper([]byte("local-key-0123456789"), failingStore{storeErr})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := mapper.ToExternal(context.Background(), "internal-42"); !errors.Is(err, storeErr) {
+		t.Errorf("expected store error, got %v", err)
+	}
+}
+
+func TestNewHMACMapper_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         []byte
+		store       Store
+		expectedErr error
+	}{
+		{name: "empty key", store: NewMemoryStore(), expectedErr: ErrShortKey},
+		{name: "short key", key: []byte("local-key"), store: NewMemoryStore(), expectedErr: ErrShortKey},
+		{name: "nil store", key: []byte("local-key-0123456789"), expectedErr: ErrNilStore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper, err := NewHMACMapper(tt.key, tt.store)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if mapper != nil {
+				t.Errorf("expected nil mapper, got %+v", mapper)
+			}
+		})
+	}
+}
+
+func TestMapperFuncs(t *testing.T) {
+	mapper, err := NewMapperFuncs(
+		func(ctx context.Context, internalID string) (string, error) { return "ext-" + internalID, nil },
+		func(ctx context.Context, externalID string) (string, error) {
+			return strings.TrimPrefix(externalID, "ext-"), nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	external, _ := mapper.ToExternal(context.Background(), "42")
+	internal, _ := mapper.ToInternal(context.Background(), external)
+	if external != "ext-42" || internal != "42" {
+		t.Errorf("unexpected mapping: %s, %s", external, internal)
+	}
+}
+
+func TestNewMapperFuncs_NilFunc(t *testing.T) {
+	lookup := func(ctx context.Context, id string) (string, error) { return id, nil }
+
+	tests := []struct {
+		name     string
+		external func(ctx context.Context, id string) (string, error)
+		internal func(ctx context.Context, id string) (string, error)
+	}{
+		{name: "missing external", internal: lookup},
+		{name: "missing internal", external: lookup},
+		{name: "missing both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMapperFuncs(tt.external, tt.internal); !errors.Is(err, ErrNilFunc) {
+				t.Errorf("expected ErrNilFunc, got %v", err)
+			}
+		})
+	}
+}
+
+type failingStore struct{ err error }
+
+func (s failingStore) Save(ctx context.Context, externalID, internalID string) error { return s.err }
+
+func (s failingStore) Lookup(ctx context.Context, externalID string) (string, error) {
+	return "", s.err
+}